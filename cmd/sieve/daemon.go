@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/daemon"
+)
+
+func newDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run sources on their configured schedules",
+		Long:  `Daemon keeps running and runs each source that has a "schedule" in the config. The config file is reloaded when it changes; SIGINT or SIGTERM stops scheduling and waits for running sources.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath, err := cmd.Flags().GetString("config")
+			if err != nil {
+				return err
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			noCache, err := cmd.Flags().GetBool("no-cache")
+			if err != nil {
+				return err
+			}
+			jitter, err := cmd.Flags().GetDuration("jitter")
+			if err != nil {
+				return err
+			}
+			shutdownTimeout, err := cmd.Flags().GetDuration("shutdown-timeout")
+			if err != nil {
+				return err
+			}
+
+			opts := runOptions{ConfigPath: configPath, DryRun: dryRun, NoCache: noCache}
+			logger := slog.New(slog.NewTextHandler(cmd.ErrOrStderr(), nil))
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			d := &daemon.Daemon{
+				ConfigPath: configPath,
+				Load:       config.Load,
+				RunSource: func(ctx context.Context, cfg *config.Config, source config.SourceConfig) error {
+					return newSourceRunner(cfg, opts, logger)(ctx, source)
+				},
+				Jitter:          jitter,
+				ShutdownTimeout: shutdownTimeout,
+				Logger:          logger,
+			}
+			logger.Info("starting daemon", "config", configPath, "dryRun", dryRun)
+			return d.Run(ctx)
+		},
+	}
+
+	cmd.Flags().String("config", "config.json", "path to config file")
+	cmd.Flags().Bool("dry-run", false, "run without persisting normal output effects")
+	cmd.Flags().Bool("no-cache", false, "bypass the on-disk LLM response cache")
+	cmd.Flags().Duration("jitter", 30*time.Second, "random delay added to each scheduled run")
+	cmd.Flags().Duration("shutdown-timeout", daemon.DefaultShutdownTimeout, "how long to wait for running sources on shutdown before cancelling them")
+	return cmd
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/site"
+)
+
+func newIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Render the landing page and OPML for generated feeds",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath, err := cmd.Flags().GetString("config")
+			if err != nil {
+				return err
+			}
+			outputDir, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+			baseURL, err := cmd.Flags().GetString("base-url")
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			entries, err := site.Build(cfg, site.Options{OutputDir: outputDir, BaseURL: baseURL})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "wrote %s and %s for %d sources\n", site.IndexFile, site.OPMLFile, len(entries))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("config", "config.json", "path to config file")
+	cmd.Flags().String("output", "output", "directory containing generated feeds")
+	cmd.Flags().String("base-url", "", "public URL of the output directory, used for absolute OPML links")
+	return cmd
+}
@@ -5,16 +5,29 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/liuerfire/sieve/internal/config"
+	httpx "github.com/liuerfire/sieve/internal/http"
 	"github.com/liuerfire/sieve/internal/llm"
 	_ "github.com/liuerfire/sieve/internal/plugins/all"
+	"github.com/liuerfire/sieve/internal/retry"
 	"github.com/liuerfire/sieve/internal/workflow"
 )
 
-type rootRunner func(cmd *cobra.Command, args []string, configPath string, dryRun bool) error
+type runOptions struct {
+	ConfigPath string
+	DryRun     bool
+	NoCache    bool
+	Resume     bool
+	All        bool
+	Parallel   int
+}
+
+type rootRunner func(cmd *cobra.Command, args []string, opts runOptions) error
 
 var runRoot rootRunner = defaultRunRoot
 
@@ -27,11 +40,39 @@ func swapRunRoot(fn rootRunner) func() {
 }
 
 func newRootCmd() *cobra.Command {
+	cmd := newRunCmd("sieve <source-name>...")
+	cmd.Short = "Run the RSS pipeline for one or more sources"
+	cmd.Long = `Sieve runs an AI-assisted RSS pipeline for the named sources, or for every configured source with --all.`
+
+	run := newRunCmd("run <source-name>...")
+	run.Short = "Run the RSS pipeline for one or more sources"
+	run.Hidden = true
+	cmd.AddCommand(run)
+	cmd.AddCommand(newDaemonCmd())
+	cmd.AddCommand(newServeCmd())
+	cmd.AddCommand(newIndexCmd())
+	cmd.AddCommand(newValidateCmd())
+	cmd.AddCommand(newPluginsCmd())
+	cmd.CompletionOptions.DisableDefaultCmd = true
+	return cmd
+}
+
+func newRunCmd(use string) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "sieve <source-name>",
-		Short: "Run the RSS pipeline for one source",
-		Long:  `Sieve runs an AI-assisted RSS pipeline for the named source.`,
-		Args:  cobra.ExactArgs(1),
+		Use: use,
+		Args: func(cmd *cobra.Command, args []string) error {
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				return err
+			}
+			if all && len(args) > 0 {
+				return fmt.Errorf("--all cannot be combined with source names")
+			}
+			if !all && len(args) == 0 {
+				return fmt.Errorf("requires at least one source name or --all")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			configPath, err := cmd.Flags().GetString("config")
 			if err != nil {
@@ -41,59 +82,150 @@ func newRootCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return runRoot(cmd, args, configPath, dryRun)
+			noCache, err := cmd.Flags().GetBool("no-cache")
+			if err != nil {
+				return err
+			}
+			resume, err := cmd.Flags().GetBool("resume")
+			if err != nil {
+				return err
+			}
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				return err
+			}
+			parallel, err := cmd.Flags().GetInt("parallel")
+			if err != nil {
+				return err
+			}
+			if parallel < 1 {
+				return fmt.Errorf("--parallel must be at least 1")
+			}
+			return runRoot(cmd, args, runOptions{
+				ConfigPath: configPath,
+				DryRun:     dryRun,
+				NoCache:    noCache,
+				Resume:     resume,
+				All:        all,
+				Parallel:   parallel,
+			})
 		},
 	}
 
 	cmd.Flags().String("config", "config.json", "path to config file")
 	cmd.Flags().Bool("dry-run", false, "run without persisting normal output effects")
+	cmd.Flags().Bool("no-cache", false, "bypass the on-disk LLM response cache")
+	cmd.Flags().Bool("resume", false, "restart from the last completed stage of an interrupted run")
+	cmd.Flags().Bool("all", false, "run every source in the config")
+	cmd.Flags().Int("parallel", 1, "number of sources to run at once")
 	return cmd
 }
 
 var rootCmd = newRootCmd()
 
-func defaultRunRoot(cmd *cobra.Command, args []string, configPath string, dryRun bool) error {
-	cfg, err := config.Load(configPath)
+func defaultRunRoot(cmd *cobra.Command, args []string, opts runOptions) error {
+	cfg, err := config.Load(opts.ConfigPath)
 	if err != nil {
 		return err
 	}
 
-	sourceName := args[0]
-	var source *config.SourceConfig
-	for i := range cfg.Sources {
-		if cfg.Sources[i].Name == sourceName {
-			source = &cfg.Sources[i]
-			break
-		}
-	}
-	if source == nil {
-		return fmt.Errorf("source %q not found in config", sourceName)
+	sources, err := selectSources(cfg, args, opts.All)
+	if err != nil {
+		return err
 	}
 
 	logger := slog.New(slog.NewTextHandler(cmd.ErrOrStderr(), nil))
-	logger.Info("starting workflow", "source", sourceName, "config", configPath, "dryRun", dryRun)
-	return workflow.Run(context.Background(), workflow.Params{
-		SourceName:          sourceName,
-		SourceConfig:        *source,
-		LLMConfig:           cfg.LLM,
-		GlobalPluginOptions: cfg.Plugins,
-		IsDryRun:            dryRun,
-		Logger:              logger,
-		LLMFactory: func(tier string) (llm.Provider, error) {
-			model := cfg.LLM.Models.Balanced
-			switch tier {
-			case "fast":
-				model = cfg.LLM.Models.Fast
-			case "powerful":
-				model = cfg.LLM.Models.Powerful
+	results := runSources(context.Background(), sources, opts.Parallel, newSourceRunner(cfg, opts, logger))
+	if len(results) == 1 {
+		return results[0].Err
+	}
+	printRunSummary(cmd.OutOrStdout(), results)
+	return runError(results)
+}
+
+func newSourceRunner(cfg *config.Config, opts runOptions, logger *slog.Logger) func(context.Context, config.SourceConfig) error {
+	httpx.SetRetryPolicy(retryPolicy(cfg.Retry.HTTP, httpx.DefaultRetryPolicy, logger.With("retry", "http")))
+	llmPolicy := retryPolicy(cfg.Retry.LLM, llm.DefaultRetryPolicy, logger.With("retry", "llm"))
+	cache := llmCache(cfg.Cache, opts)
+	return func(ctx context.Context, source config.SourceConfig) error {
+		sourceLogger := logger.With("source", source.Name)
+		sourceLogger.Info("starting workflow", "config", opts.ConfigPath, "dryRun", opts.DryRun)
+		return workflow.Run(ctx, workflow.Params{
+			SourceName:          source.Name,
+			SourceConfig:        source,
+			LLMConfig:           cfg.LLM,
+			GlobalPluginOptions: cfg.Plugins,
+			IsDryRun:            opts.DryRun,
+			Resume:              opts.Resume,
+			Logger:              sourceLogger,
+			LLMFactory:          newLLMFactory(cfg.LLM, llmPolicy, cache, sourceLogger),
+		})
+	}
+}
+
+func llmCache(cfg config.CacheConfig, opts runOptions) *llm.Cache {
+	if opts.NoCache {
+		return nil
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Join("output", "llm-cache")
+	}
+	cache := llm.NewCache(dir, time.Duration(cfg.TTL))
+	cache.ReadOnly = opts.DryRun
+	return cache
+}
+
+func newLLMFactory(cfg config.LLMConfig, policy retry.Policy, cache *llm.Cache, logger *slog.Logger) func(tier string) (llm.Provider, error) {
+	return func(tier string) (llm.Provider, error) {
+		chain := cfg.TierChain(tier)
+		backends := make([]llm.Backend, 0, len(chain))
+		for _, resolved := range chain {
+			llmCfg := llmConfigForTier(resolved)
+			llmCfg.Retry = policy
+			provider, err := llm.CreateProvider(llmCfg)
+			if err != nil {
+				return nil, err
 			}
-			return llm.CreateProvider(llm.Config{
-				Provider: cfg.LLM.Provider,
-				Model:    model,
-				BaseURL:  cfg.LLM.BaseURL,
+			if cache != nil {
+				provider = llm.CachedProvider{Provider: provider, Cache: cache, Config: llmCfg}
+			}
+			backends = append(backends, llm.Backend{
+				Name:     llm.BackendName(llmCfg),
+				Provider: provider,
 			})
-		},
-	})
+		}
+		return llm.FallbackProvider{Backends: backends, Logger: logger}, nil
+	}
+}
+
+func llmConfigForTier(resolved config.LLMTier) llm.Config {
+	return llm.Config{
+		Provider:    resolved.Provider,
+		Model:       resolved.Model,
+		BaseURL:     resolved.BaseURL,
+		APIKeyEnv:   resolved.APIKeyEnv,
+		Headers:     resolved.Headers,
+		Temperature: resolved.Temperature,
+		MaxTokens:   resolved.MaxTokens,
+	}
+}
+
+func retryPolicy(cfg config.RetryPolicyConfig, defaults retry.Policy, logger *slog.Logger) retry.Policy {
+	policy := defaults
+	if cfg.MaxRetries != nil {
+		policy.MaxRetries = *cfg.MaxRetries
+	}
+	if cfg.BaseDelay > 0 {
+		policy.BaseDelay = time.Duration(cfg.BaseDelay)
+	}
+	if cfg.MaxDelay > 0 {
+		policy.MaxDelay = time.Duration(cfg.MaxDelay)
+	}
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		logger.Warn("retrying after error", "attempt", attempt, "delay", delay, "error", err)
+	}
+	return policy
 }
 
 func Execute() {
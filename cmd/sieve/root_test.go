@@ -3,10 +3,13 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"github.com/liuerfire/sieve/internal/config"
 )
 
 func executeCommand(root *cobra.Command, args ...string) (output string, err error) {
@@ -34,6 +37,18 @@ func TestRootCmd_Help(t *testing.T) {
 	}
 }
 
+func TestRootCmd_SubcommandsAreReservedSourceNames(t *testing.T) {
+	root := newRootCmd()
+	root.InitDefaultHelpCmd()
+	for _, sub := range root.Commands() {
+		for _, name := range append([]string{sub.Name()}, sub.Aliases...) {
+			if !slices.Contains(config.ReservedSourceNames, name) {
+				t.Errorf("subcommand %q is missing from config.ReservedSourceNames", name)
+			}
+		}
+	}
+}
+
 func TestRootCmd_HelpOmitsReport(t *testing.T) {
 	root := newRootCmd()
 	output, err := executeCommand(root, "--help")
@@ -66,7 +81,7 @@ func TestRootCommand_RequiresSourceName(t *testing.T) {
 	if !strings.Contains(output, "Usage:") {
 		t.Fatalf("expected usage output, got %q", output)
 	}
-	if !strings.Contains(output, "accepts 1 arg(s), received 0") {
+	if !strings.Contains(output, "requires at least one source name or --all") {
 		t.Fatalf("expected missing arg error, got %q", output)
 	}
 }
@@ -75,22 +90,28 @@ func TestRootCommand_ParsesConfigAndDryRun(t *testing.T) {
 	root := newRootCmd()
 
 	called := false
-	restore := swapRunRoot(func(_ *cobra.Command, args []string, configPath string, dryRun bool) error {
+	restore := swapRunRoot(func(_ *cobra.Command, args []string, opts runOptions) error {
 		called = true
 		if len(args) != 1 || args[0] != "hacker-news" {
 			t.Fatalf("unexpected args: %#v", args)
 		}
-		if configPath != "custom.json" {
-			t.Fatalf("unexpected config path: %q", configPath)
+		if opts.ConfigPath != "custom.json" {
+			t.Fatalf("unexpected config path: %q", opts.ConfigPath)
 		}
-		if !dryRun {
+		if !opts.DryRun {
 			t.Fatal("expected dry-run to be true")
 		}
+		if !opts.NoCache {
+			t.Fatal("expected no-cache to be true")
+		}
+		if !opts.Resume {
+			t.Fatal("expected resume to be true")
+		}
 		return nil
 	})
 	defer restore()
 
-	output, err := executeCommand(root, "hacker-news", "--config", "custom.json", "--dry-run")
+	output, err := executeCommand(root, "hacker-news", "--config", "custom.json", "--dry-run", "--no-cache", "--resume")
 	if err != nil {
 		t.Fatalf("expected no error, got %v with output %q", err, output)
 	}
@@ -151,3 +172,155 @@ func TestCLI_RunWorkflowForNamedSource(t *testing.T) {
 		t.Fatalf("expected completion log, got %q", output)
 	}
 }
+
+func TestRunCommand_ParsesAllAndParallel(t *testing.T) {
+	root := newRootCmd()
+
+	var got runOptions
+	restore := swapRunRoot(func(_ *cobra.Command, args []string, opts runOptions) error {
+		if len(args) != 0 {
+			t.Fatalf("unexpected args: %#v", args)
+		}
+		got = opts
+		return nil
+	})
+	defer restore()
+
+	output, err := executeCommand(root, "run", "--all", "--parallel", "3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v with output %q", err, output)
+	}
+	if !got.All || got.Parallel != 3 {
+		t.Fatalf("unexpected options: %#v", got)
+	}
+}
+
+func TestRootCommand_RejectsAllWithSourceNames(t *testing.T) {
+	root := newRootCmd()
+	output, err := executeCommand(root, "hacker-news", "--all")
+	if err == nil || !strings.Contains(output, "--all cannot be combined with source names") {
+		t.Fatalf("expected conflicting args error, got %v with output %q", err, output)
+	}
+}
+
+func TestCLI_RunsSeveralSourcesAndReportsFailures(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+
+	err := os.WriteFile(configPath, []byte(`{
+  "llm": {
+    "provider": "openai",
+    "models": {"fast": "gpt-fast", "balanced": "gpt-balanced", "powerful": "gpt-powerful"}
+  },
+  "plugins": {
+    "builtin/reporter-rss": {"outputPath": "`+filepath.Join(dir, "feed.xml")+`"}
+  },
+  "sources": [
+    {"name": "good", "plugins": ["builtin/reporter-rss"]},
+    {"name": "broken", "plugins": ["missing/plugin"]},
+    {"name": "also-good", "plugins": ["builtin/reporter-rss"]}
+  ]
+}`), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	root := newRootCmd()
+	output, err := executeCommand(root, "--all", "--parallel", "2", "--config", configPath, "--dry-run")
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 sources failed") {
+		t.Fatalf("expected failure summary error, got %v", err)
+	}
+	for _, want := range []string{"source=good", "source=also-good", "SOURCE", "broken", "failed", `plugin "missing/plugin" not found`} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output, got %q", want, output)
+		}
+	}
+	if strings.Count(output, "workflow completed") != 2 {
+		t.Fatalf("expected both healthy sources to complete, got %q", output)
+	}
+}
+
+func TestExampleConfig_MatchesPluginOptionSchemas(t *testing.T) {
+	if _, err := config.Load(filepath.Join("..", "..", "config.json")); err != nil {
+		t.Fatalf("example config does not validate: %v", err)
+	}
+}
+
+func TestValidateCommand_ReportsProblems(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	err := os.WriteFile(configPath, []byte(`{
+  "llm": {
+    "provider": "openai",
+    "models": {"fast": "gpt-fast", "balanced": "gpt-balanced", "powerful": "gpt-powerful"}
+  },
+  "sources": [
+    {"name": "news", "plugins": ["builtin/collect-rss", "missing/plugin"]}
+  ]
+}`), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	output, err := executeCommand(newRootCmd(), "validate", "--config", configPath)
+	if err == nil || !strings.Contains(err.Error(), "2 problems found") {
+		t.Fatalf("expected problem count error, got %v", err)
+	}
+	for _, want := range []string{`missing required option "url"`, `plugin "missing/plugin" not found`} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output, got %q", want, output)
+		}
+	}
+}
+
+func TestValidateCommand_ReportsConfigProblemsTogether(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	err := os.WriteFile(configPath, []byte(`{
+  "llm": {
+    "provider": "openai",
+    "models": {"fast": "gpt-fast", "balanced": "gpt-balanced", "powerful": "gpt-powerful"}
+  },
+  "sources": [
+    {"name": "news", "plugins": [
+      {"name": "builtin/collect-rss", "options": {"url": "https://example.com/rss", "maxitems": 5}},
+      "builtin/reporter-rss",
+      "missing/plugin",
+      {"name": "builtin/clean-text", "onError": "sometimes"}
+    ]}
+  ]
+}`), 0o644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	output, err := executeCommand(newRootCmd(), "validate", "--config", configPath)
+	if err == nil || !strings.Contains(err.Error(), "4 problems found") {
+		t.Fatalf("expected problem count error, got %v\n%s", err, output)
+	}
+	for _, want := range []string{
+		`unknown option "maxitems"`,
+		`missing required option "outputPath"`,
+		`plugin "missing/plugin" not found`,
+		`unsupported onError "sometimes"`,
+	} {
+		if strings.Count(output, want) != 1 {
+			t.Fatalf("expected %q once in output, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "Usage:") || strings.Contains(output, "problems found") {
+		t.Fatalf("expected only the problems in output, got %q", output)
+	}
+}
+
+func TestPluginsCommand_ListsRegistry(t *testing.T) {
+	output, err := executeCommand(newRootCmd(), "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"NAME", "builtin/collect-rss", "url (string, required)", "producthunt", "PRODUCTHUNT_API_KEY"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output, got %q", want, output)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/config"
+)
+
+type sourceResult struct {
+	Source   string
+	Duration time.Duration
+	Err      error
+}
+
+func selectSources(cfg *config.Config, names []string, all bool) ([]config.SourceConfig, error) {
+	if all {
+		return cfg.Sources, nil
+	}
+	sources := make([]config.SourceConfig, 0, len(names))
+	for _, name := range names {
+		var source *config.SourceConfig
+		for i := range cfg.Sources {
+			if cfg.Sources[i].Name == name {
+				source = &cfg.Sources[i]
+				break
+			}
+		}
+		if source == nil {
+			return nil, fmt.Errorf("source %q not found in config", name)
+		}
+		sources = append(sources, *source)
+	}
+	return sources, nil
+}
+
+// runSources runs each source with at most parallel workflows at a time. A
+// failing source never stops the others; results keep the input order.
+func runSources(ctx context.Context, sources []config.SourceConfig, parallel int, run func(context.Context, config.SourceConfig) error) []sourceResult {
+	results := make([]sourceResult, len(sources))
+	sem := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			err := run(ctx, source)
+			results[i] = sourceResult{Source: source.Name, Duration: time.Since(start), Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func printRunSummary(w io.Writer, results []sourceResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tSTATUS\tDURATION\tERROR")
+	for _, result := range results {
+		status := "ok"
+		message := ""
+		if result.Err != nil {
+			status = "failed"
+			message = result.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Source, status, result.Duration.Round(time.Millisecond), message)
+	}
+	_ = tw.Flush()
+}
+
+func runError(results []sourceResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sources failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/server"
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve generated feeds and trigger source runs over HTTP",
+		Long:  `Serve exposes the generated feeds and pages over HTTP, lists configured sources at GET /sources and starts a run with POST /sources/{name}/run. It listens on localhost unless --addr says otherwise; set --token before exposing it.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath, err := cmd.Flags().GetString("config")
+			if err != nil {
+				return err
+			}
+			addr, err := cmd.Flags().GetString("addr")
+			if err != nil {
+				return err
+			}
+			outputDir, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			noCache, err := cmd.Flags().GetBool("no-cache")
+			if err != nil {
+				return err
+			}
+			token, err := cmd.Flags().GetString("token")
+			if err != nil {
+				return err
+			}
+			if token == "" {
+				token = os.Getenv("SIEVE_SERVE_TOKEN")
+			}
+
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			opts := runOptions{ConfigPath: configPath, DryRun: dryRun, NoCache: noCache}
+			logger := slog.New(slog.NewTextHandler(cmd.ErrOrStderr(), nil))
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			srv := server.New(ctx, outputDir, cfg.Sources, newSourceRunner(cfg, opts, logger), logger)
+			srv.Token = token
+			httpServer := &http.Server{
+				Addr:              addr,
+				Handler:           srv.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			errs := make(chan error, 1)
+			go func() {
+				logger.Info("serving", "addr", addr, "output", outputDir, "config", configPath, "tokenRequired", token != "")
+				errs <- httpServer.ListenAndServe()
+			}()
+
+			select {
+			case err := <-errs:
+				return err
+			case <-ctx.Done():
+			}
+			logger.Info("shutting down server")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			srv.Wait()
+			return nil
+		},
+	}
+
+	cmd.Flags().String("config", "config.json", "path to config file")
+	cmd.Flags().String("addr", "127.0.0.1:8080", "address to listen on")
+	cmd.Flags().String("output", "output", "directory to serve")
+	cmd.Flags().Bool("dry-run", false, "run without persisting normal output effects")
+	cmd.Flags().Bool("no-cache", false, "bypass the on-disk LLM response cache")
+	cmd.Flags().String("token", "", "bearer token required to trigger runs (default $SIEVE_SERVE_TOKEN)")
+	return cmd
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/llm"
+	"github.com/liuerfire/sieve/internal/plugins"
+	"github.com/liuerfire/sieve/internal/validate"
+)
+
+func newValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check a config without running any source",
+		Long:  `Validate loads the config and reports every problem a run would hit before fetching anything: invalid config values, unknown plugins, missing required options or environment variables, phases a plugin does not implement, unwritable output paths and LLM tiers that cannot be built.`,
+		Args:  cobra.NoArgs,
+		// The problems are already listed on stdout; Execute prints the
+		// summary error once.
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configPath, err := cmd.Flags().GetString("config")
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.Read(configPath)
+			if err != nil {
+				return err
+			}
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			problems := validate.Config(cfg, validate.Options{
+				LLM:       newLLMFactory(cfg.LLM, llm.DefaultRetryPolicy, nil, logger),
+				OutputDir: "output",
+			})
+			out := cmd.OutOrStdout()
+			for _, problem := range problems {
+				fmt.Fprintln(out, problem)
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%d problems found in %s", len(problems), configPath)
+			}
+			fmt.Fprintf(out, "%s: %d sources OK\n", configPath, len(cfg.Sources))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("config", "config.json", "path to config file")
+	return cmd
+}
+
+func newPluginsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "plugins",
+		Short: "List registered plugins with their phases and options",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			printPlugins(cmd.OutOrStdout(), plugins.Registered())
+			return nil
+		},
+	}
+}
+
+func printPlugins(w io.Writer, registrations []plugins.Registration) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPHASES\tOPTIONS\tENV")
+	for _, registration := range registrations {
+		var options []string
+		if schema, ok := config.LookupOptions(registration.Name); ok {
+			for _, field := range schema.Fields() {
+				option := field.Name + " (" + field.Type
+				if field.Required {
+					option += ", required"
+				}
+				options = append(options, option+")")
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			registration.Name,
+			orDash(strings.Join(registration.Spec.Phases, ",")),
+			orDash(strings.Join(options, ", ")),
+			orDash(strings.Join(registration.Spec.Env, ",")),
+		)
+	}
+	_ = tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -2,8 +2,17 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/schedule"
 )
 
 var validProviders = map[string]struct{}{
@@ -13,36 +22,253 @@ var validProviders = map[string]struct{}{
 	"qwen":       {},
 	"openrouter": {},
 	"grok":       {},
+	"local":      {},
 }
 
 type Config struct {
 	LLM     LLMConfig                  `json:"llm"`
+	Retry   RetryConfig                `json:"retry"`
+	Cache   CacheConfig                `json:"cache"`
+	Include []string                   `json:"include,omitempty"`
 	Plugins map[string]json.RawMessage `json:"plugins,omitempty"`
 	Sources []SourceConfig             `json:"sources"`
+
+	// Files lists every file Load read, the main config first.
+	Files []string `json:"-"`
+}
+
+type RetryConfig struct {
+	LLM  RetryPolicyConfig `json:"llm"`
+	HTTP RetryPolicyConfig `json:"http"`
+}
+
+type RetryPolicyConfig struct {
+	MaxRetries *int     `json:"maxRetries,omitempty"`
+	BaseDelay  Duration `json:"baseDelay,omitempty"`
+	MaxDelay   Duration `json:"maxDelay,omitempty"`
+}
+
+type CacheConfig struct {
+	Dir string   `json:"dir,omitempty"`
+	TTL Duration `json:"ttl,omitempty"`
+}
+
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", text, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// StringList is a list option that may also be written as one string, such
+// as a comma-separated list of topics. The array form can be extended per
+// source with a "+" key; see MergeOptions.
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*l = nil
+		if text != "" {
+			*l = StringList{text}
+		}
+		return nil
+	}
+	var items []string
+	if err := json.Unmarshal(data, &items); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return &json.UnmarshalTypeError{Value: typeErr.Value, Type: reflect.TypeFor[StringList]()}
+		}
+		return err
+	}
+	*l = items
+	return nil
+}
+
+// String joins the list with commas.
+func (l StringList) String() string {
+	return strings.Join(l, ",")
 }
 
 type LLMConfig struct {
-	Provider string    `json:"provider"`
-	BaseURL  string    `json:"baseUrl,omitempty"`
-	Models   LLMModels `json:"models"`
+	Provider string            `json:"provider"`
+	BaseURL  string            `json:"baseUrl,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Models   LLMModels         `json:"models"`
+	Pricing  map[string]Price  `json:"pricing,omitempty"`
+}
+
+// Price is USD per million input and output tokens, keyed in llm.pricing by
+// "provider/model" or bare model name.
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
 }
 
 type LLMModels struct {
-	Fast     string `json:"fast"`
-	Balanced string `json:"balanced"`
-	Powerful string `json:"powerful"`
+	Fast     LLMTier `json:"fast"`
+	Balanced LLMTier `json:"balanced"`
+	Powerful LLMTier `json:"powerful"`
+}
+
+type LLMTier struct {
+	Model       string            `json:"model"`
+	Provider    string            `json:"provider,omitempty"`
+	BaseURL     string            `json:"baseUrl,omitempty"`
+	APIKeyEnv   string            `json:"apiKeyEnv,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Temperature *float64          `json:"temperature,omitempty"`
+	MaxTokens   int               `json:"maxTokens,omitempty"`
+	Fallbacks   []LLMTier         `json:"fallbacks,omitempty"`
+}
+
+func (t *LLMTier) UnmarshalJSON(data []byte) error {
+	var model string
+	if err := json.Unmarshal(data, &model); err == nil {
+		*t = LLMTier{Model: model}
+		return nil
+	}
+
+	type tierAlias LLMTier
+	var tier tierAlias
+	if err := json.Unmarshal(data, &tier); err != nil {
+		return fmt.Errorf("invalid llm model tier: %w", err)
+	}
+	*t = LLMTier(tier)
+	return nil
+}
+
+func (c LLMConfig) Tier(name string) LLMTier {
+	tier := c.Models.Balanced
+	switch name {
+	case "fast":
+		tier = c.Models.Fast
+	case "powerful":
+		tier = c.Models.Powerful
+	}
+	return tier.inherit(LLMTier{
+		Provider: c.Provider,
+		BaseURL:  c.BaseURL,
+		Headers:  c.Headers,
+	})
+}
+
+func (c LLMConfig) TierChain(name string) []LLMTier {
+	primary := c.Tier(name)
+	chain := make([]LLMTier, 0, len(primary.Fallbacks)+1)
+	chain = append(chain, primary)
+	for _, fallback := range primary.Fallbacks {
+		resolved := fallback.inherit(primary)
+		resolved.Fallbacks = nil
+		chain = append(chain, resolved)
+	}
+	chain[0].Fallbacks = nil
+	return chain
+}
+
+func (t LLMTier) inherit(base LLMTier) LLMTier {
+	if t.Provider == "" {
+		t.Provider = base.Provider
+	}
+	if t.Provider != base.Provider {
+		return t
+	}
+	if t.BaseURL == "" {
+		t.BaseURL = base.BaseURL
+	}
+	if t.APIKeyEnv == "" {
+		t.APIKeyEnv = base.APIKeyEnv
+	}
+	if t.Temperature == nil {
+		t.Temperature = base.Temperature
+	}
+	if t.MaxTokens == 0 {
+		t.MaxTokens = base.MaxTokens
+	}
+	if len(base.Headers) > 0 {
+		headers := make(map[string]string, len(base.Headers)+len(t.Headers))
+		maps.Copy(headers, base.Headers)
+		maps.Copy(headers, t.Headers)
+		t.Headers = headers
+	}
+	return t
 }
 
 type SourceConfig struct {
-	Name    string        `json:"name"`
-	Title   string        `json:"title,omitempty"`
-	Context string        `json:"context,omitempty"`
-	Plugins []PluginEntry `json:"plugins"`
+	Name        string        `json:"name"`
+	Title       string        `json:"title,omitempty"`
+	Context     string        `json:"context,omitempty"`
+	Schedule    string        `json:"schedule,omitempty"`
+	Budget      *Budget       `json:"budget,omitempty"`
+	MaxDuration Duration      `json:"maxDuration,omitempty"`
+	Prefix      []PluginEntry `json:"prefix,omitempty"`
+	Plugins     []PluginEntry `json:"plugins"`
+}
+
+type Budget struct {
+	MaxTokens int     `json:"maxTokens,omitempty"`
+	MaxCost   float64 `json:"maxCost,omitempty"`
 }
 
 type PluginEntry struct {
 	Name    string          `json:"name"`
 	Options json.RawMessage `json:"options,omitempty"`
+	OnError string          `json:"onError,omitempty"`
+	Timeout Duration        `json:"timeout,omitempty"`
+	Phases  []string        `json:"phases,omitempty"`
+}
+
+const (
+	PhaseCollect = "collect"
+	PhaseProcess = "process"
+	PhaseReport  = "report"
+)
+
+// RunsIn reports whether the entry takes part in phase. An entry without
+// phases runs in all of them.
+func (e PluginEntry) RunsIn(phase string) bool {
+	return len(e.Phases) == 0 || slices.Contains(e.Phases, phase)
+}
+
+// ErrorPolicy is the parsed form of PluginEntry.OnError: "fail", "skip" or
+// "retry:N". A retry policy fails once its retries are exhausted.
+type ErrorPolicy struct {
+	Skip    bool
+	Retries int
+}
+
+func ParseErrorPolicy(value string) (ErrorPolicy, error) {
+	switch value {
+	case "fail":
+		return ErrorPolicy{}, nil
+	case "skip":
+		return ErrorPolicy{Skip: true}, nil
+	}
+	if count, ok := strings.CutPrefix(value, "retry:"); ok {
+		retries, err := strconv.Atoi(count)
+		if err == nil && retries > 0 {
+			return ErrorPolicy{Retries: retries}, nil
+		}
+	}
+	return ErrorPolicy{}, fmt.Errorf(`unsupported onError %q: want "fail", "skip" or "retry:N"`, value)
 }
 
 func (e *PluginEntry) UnmarshalJSON(data []byte) error {
@@ -65,47 +291,211 @@ func (e *PluginEntry) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Parse reads a JSON config. See ParseFormat.
 func Parse(data []byte) (*Config, error) {
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	return ParseFormat(data, FormatJSON)
+}
+
+// ParseFormat is Parse for a config written in format. Includes are resolved
+// relative to the current directory.
+func ParseFormat(data []byte, format Format) (*Config, error) {
+	cfg, err := load("", data, format)
+	if err != nil {
 		return nil, err
 	}
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
-	return &cfg, nil
+	return cfg, nil
 }
 
+// Load reads the config at path in the format its extension names, along
+// with every file it includes. Includes are resolved relative to the file
+// that names them.
 func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Read is Load without Validate, for callers that report validation
+// problems themselves.
+func Read(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	return Parse(data)
+	return load(path, data, FormatOf(path))
 }
 
+// ReservedSourceNames are the sieve subcommands. The CLI takes source names as
+// positional arguments, so a source named after one could never be run.
+var ReservedSourceNames = []string{"run", "daemon", "serve", "index", "validate", "plugins", "help", "completion"}
+
+// Validate checks the config and returns every problem found, joined with
+// errors.Join, rather than stopping at the first.
 func (c *Config) Validate() error {
-	if _, ok := validProviders[c.LLM.Provider]; !ok {
-		return fmt.Errorf("unsupported llm.provider %q", c.LLM.Provider)
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
-	if c.LLM.Models.Fast == "" || c.LLM.Models.Balanced == "" || c.LLM.Models.Powerful == "" {
-		return fmt.Errorf("llm.models.fast, llm.models.balanced, and llm.models.powerful are required")
+	add(c.LLM.validate())
+	add(c.Retry.LLM.validate("retry.llm"))
+	add(c.Retry.HTTP.validate("retry.http"))
+	if c.Cache.TTL < 0 {
+		add(fmt.Errorf("cache.ttl must not be negative"))
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Plugins)) {
+		options, err := MergeOptions(nil, c.Plugins[name])
+		if err == nil {
+			err = validateOptions(name, options)
+		}
+		if err != nil {
+			add(fmt.Errorf("plugins[%q]: %w", name, err))
+		}
 	}
 	if len(c.Sources) == 0 {
-		return fmt.Errorf("at least one source is required")
+		add(fmt.Errorf("at least one source is required"))
 	}
+	names := map[string]struct{}{}
 	for i, src := range c.Sources {
 		if src.Name == "" {
-			return fmt.Errorf("source[%d]: name is required", i)
+			add(fmt.Errorf("source[%d]: name is required", i))
+		} else if _, ok := names[src.Name]; ok {
+			add(fmt.Errorf("source[%d]: duplicate name %q", i, src.Name))
+		} else if slices.Contains(ReservedSourceNames, src.Name) {
+			add(fmt.Errorf("source[%d]: name %q is reserved for the sieve %s command", i, src.Name, src.Name))
 		}
+		names[src.Name] = struct{}{}
 		if len(src.Plugins) == 0 {
-			return fmt.Errorf("source[%d]: at least one plugin is required", i)
+			add(fmt.Errorf("source[%d]: at least one plugin is required", i))
+		}
+		if src.Schedule != "" {
+			if _, err := schedule.Parse(src.Schedule); err != nil {
+				add(fmt.Errorf("source[%d].schedule: %w", i, err))
+			}
+		}
+		if src.MaxDuration < 0 {
+			add(fmt.Errorf("source[%d].maxDuration must not be negative", i))
+		}
+		if src.Budget != nil {
+			if src.Budget.MaxTokens < 0 || src.Budget.MaxCost < 0 {
+				add(fmt.Errorf("source[%d].budget: limits must not be negative", i))
+			}
+			if src.Budget.MaxCost > 0 && len(c.LLM.Pricing) == 0 {
+				add(fmt.Errorf("source[%d].budget.maxCost requires llm.pricing", i))
+			}
+		}
+		for j, plugin := range src.Prefix {
+			add(plugin.validate(fmt.Sprintf("source[%d].prefix[%d]", i, j)))
+			if len(plugin.Phases) > 0 {
+				add(fmt.Errorf("source[%d].prefix[%d].phases: prefix plugins only run in the process phase", i, j))
+			}
 		}
 		for j, plugin := range src.Plugins {
-			if plugin.Name == "" {
-				return fmt.Errorf("source[%d].plugins[%d]: name is required", i, j)
+			add(plugin.validate(fmt.Sprintf("source[%d].plugins[%d]", i, j)))
+		}
+		for _, plugin := range append(slices.Clone(src.Prefix), src.Plugins...) {
+			options, err := MergeOptions(c.Plugins[plugin.Name], plugin.Options)
+			if err == nil {
+				err = validateOptions(plugin.Name, options)
+			}
+			if err != nil {
+				add(fmt.Errorf("source %q: plugin %q: %w", src.Name, plugin.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e PluginEntry) validate(path string) error {
+	if e.Name == "" {
+		return fmt.Errorf("%s: name is required", path)
+	}
+	if e.OnError != "" {
+		if _, err := ParseErrorPolicy(e.OnError); err != nil {
+			return fmt.Errorf("%s.onError: %w", path, err)
+		}
+	}
+	if e.Timeout < 0 {
+		return fmt.Errorf("%s.timeout must not be negative", path)
+	}
+	for _, phase := range e.Phases {
+		switch phase {
+		case PhaseCollect, PhaseProcess, PhaseReport:
+		default:
+			return fmt.Errorf("%s.phases: unsupported phase %q", path, phase)
+		}
+	}
+	return nil
+}
+
+func (c LLMConfig) validate() error {
+	if _, ok := validProviders[c.Provider]; c.Provider != "" && !ok {
+		return fmt.Errorf("unsupported llm.provider %q", c.Provider)
+	}
+	for model, price := range c.Pricing {
+		if price.Input < 0 || price.Output < 0 {
+			return fmt.Errorf("llm.pricing[%q]: prices must not be negative", model)
+		}
+	}
+	if c.Models.Fast.Model == "" || c.Models.Balanced.Model == "" || c.Models.Powerful.Model == "" {
+		return fmt.Errorf("llm.models.fast, llm.models.balanced, and llm.models.powerful are required")
+	}
+	for _, name := range []string{"fast", "balanced", "powerful"} {
+		for i, tier := range c.Tier(name).Fallbacks {
+			if len(tier.Fallbacks) > 0 {
+				return fmt.Errorf("llm.models.%s.fallbacks[%d]: nested fallbacks are not supported", name, i)
 			}
 		}
+		for i, tier := range c.TierChain(name) {
+			path := "llm.models." + name
+			if i > 0 {
+				path = fmt.Sprintf("%s.fallbacks[%d]", path, i-1)
+			}
+			if err := tier.validate(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t LLMTier) validate(path string) error {
+	if t.Model == "" {
+		return fmt.Errorf("%s: model is required", path)
+	}
+	if t.Provider == "" {
+		return fmt.Errorf("%s: provider is required when llm.provider is not set", path)
+	}
+	if _, ok := validProviders[t.Provider]; !ok {
+		return fmt.Errorf("%s: unsupported provider %q", path, t.Provider)
+	}
+	if t.Temperature != nil && (*t.Temperature < 0 || *t.Temperature > 2) {
+		return fmt.Errorf("%s: temperature must be between 0 and 2", path)
+	}
+	if t.MaxTokens < 0 {
+		return fmt.Errorf("%s: maxTokens must not be negative", path)
+	}
+	return nil
+}
+
+func (c RetryPolicyConfig) validate(path string) error {
+	if c.MaxRetries != nil && *c.MaxRetries < 0 {
+		return fmt.Errorf("%s.maxRetries must not be negative", path)
+	}
+	if c.BaseDelay < 0 || c.MaxDelay < 0 {
+		return fmt.Errorf("%s: delays must not be negative", path)
+	}
+	if c.MaxDelay > 0 && c.BaseDelay > c.MaxDelay {
+		return fmt.Errorf("%s.baseDelay must not exceed maxDelay", path)
 	}
 	return nil
 }
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParse_ValidConfig(t *testing.T) {
@@ -46,8 +48,8 @@ func TestParse_ValidConfig(t *testing.T) {
 	if cfg.LLM.Provider != "openai" {
 		t.Fatalf("expected provider openai, got %q", cfg.LLM.Provider)
 	}
-	if cfg.LLM.Models.Balanced != "gpt-balanced" {
-		t.Fatalf("expected balanced model gpt-balanced, got %q", cfg.LLM.Models.Balanced)
+	if cfg.LLM.Models.Balanced.Model != "gpt-balanced" {
+		t.Fatalf("expected balanced model gpt-balanced, got %q", cfg.LLM.Models.Balanced.Model)
 	}
 	if len(cfg.Sources) != 1 {
 		t.Fatalf("expected 1 source, got %d", len(cfg.Sources))
@@ -143,3 +145,366 @@ func TestParse_AcceptsQwenProvider(t *testing.T) {
 		t.Fatalf("expected provider qwen, got %q", cfg.LLM.Provider)
 	}
 }
+
+func TestParse_PerTierProviders(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"llm": {
+			"provider": "openrouter",
+			"baseUrl": "https://proxy.example.com/v1",
+			"headers": {"X-Title": "sieve"},
+			"models": {
+				"fast": {
+					"provider": "local",
+					"model": "llama3",
+					"baseUrl": "http://localhost:8080/v1",
+					"temperature": 0.2,
+					"maxTokens": 2048
+				},
+				"balanced": "openrouter-balanced",
+				"powerful": {
+					"provider": "anthropic",
+					"model": "claude-powerful",
+					"apiKeyEnv": "SIEVE_ANTHROPIC_KEY"
+				}
+			}
+		},
+		"sources": [
+			{
+				"name": "hacker-news",
+				"plugins": ["builtin/reporter-rss"]
+			}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	fast := cfg.LLM.Tier("fast")
+	if fast.Provider != "local" || fast.Model != "llama3" || fast.BaseURL != "http://localhost:8080/v1" {
+		t.Fatalf("unexpected fast tier: %#v", fast)
+	}
+	if fast.Temperature == nil || *fast.Temperature != 0.2 || fast.MaxTokens != 2048 {
+		t.Fatalf("unexpected fast tier sampling: %#v", fast)
+	}
+	if len(fast.Headers) != 0 {
+		t.Fatalf("expected other provider not to inherit headers, got %#v", fast.Headers)
+	}
+
+	balanced := cfg.LLM.Tier("balanced")
+	if balanced.Provider != "openrouter" || balanced.BaseURL != "https://proxy.example.com/v1" || balanced.Headers["X-Title"] != "sieve" {
+		t.Fatalf("expected balanced tier to inherit top-level settings, got %#v", balanced)
+	}
+
+	powerful := cfg.LLM.Tier("powerful")
+	if powerful.Provider != "anthropic" || powerful.BaseURL != "" || powerful.APIKeyEnv != "SIEVE_ANTHROPIC_KEY" {
+		t.Fatalf("unexpected powerful tier: %#v", powerful)
+	}
+}
+
+func TestParse_RejectsInvalidTier(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		models string
+		want   string
+	}{
+		{
+			name:   "unknown provider",
+			models: `{"fast": {"provider": "nope", "model": "a"}, "balanced": "b", "powerful": "c"}`,
+			want:   `llm.models.fast: unsupported provider "nope"`,
+		},
+		{
+			name:   "temperature out of range",
+			models: `{"fast": "a", "balanced": {"model": "b", "temperature": 3}, "powerful": "c"}`,
+			want:   "llm.models.balanced: temperature must be between 0 and 2",
+		},
+		{
+			name:   "missing model",
+			models: `{"fast": "a", "balanced": "b", "powerful": {"provider": "openai"}}`,
+			want:   "llm.models.fast, llm.models.balanced, and llm.models.powerful are required",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(`{
+				"llm": {"provider": "openai", "models": ` + tc.models + `},
+				"sources": [{"name": "s", "plugins": ["builtin/reporter-rss"]}]
+			}`))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestParse_AllowsMissingTopLevelProviderWhenTiersSetIt(t *testing.T) {
+	_, err := Parse([]byte(`{
+		"llm": {
+			"models": {
+				"fast": {"provider": "local", "model": "a"},
+				"balanced": {"provider": "local", "model": "b"},
+				"powerful": {"provider": "local", "model": "c"}
+			}
+		},
+		"sources": [{"name": "s", "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+}
+
+func TestLLMConfig_TierChainResolvesFallbacks(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"llm": {
+			"provider": "qwen",
+			"baseUrl": "https://dashscope.example.com/v1",
+			"models": {
+				"fast": "qwen-turbo",
+				"balanced": {
+					"model": "qwen-plus",
+					"fallbacks": [
+						"qwen-turbo",
+						{"provider": "local", "model": "llama3"}
+					]
+				},
+				"powerful": "qwen-max"
+			}
+		},
+		"sources": [{"name": "s", "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	chain := cfg.LLM.TierChain("balanced")
+	if len(chain) != 3 {
+		t.Fatalf("expected 3 backends, got %#v", chain)
+	}
+	if chain[1].Provider != "qwen" || chain[1].Model != "qwen-turbo" || chain[1].BaseURL != "https://dashscope.example.com/v1" {
+		t.Fatalf("expected model fallback to inherit provider, got %#v", chain[1])
+	}
+	if chain[2].Provider != "local" || chain[2].BaseURL != "" {
+		t.Fatalf("unexpected provider fallback: %#v", chain[2])
+	}
+	if len(cfg.LLM.TierChain("fast")) != 1 {
+		t.Fatal("expected fast tier without fallbacks")
+	}
+}
+
+func TestParse_RejectsInvalidFallback(t *testing.T) {
+	_, err := Parse([]byte(`{
+		"llm": {
+			"provider": "qwen",
+			"models": {
+				"fast": "a",
+				"balanced": {"model": "b", "fallbacks": [{"provider": "nope", "model": "c"}]},
+				"powerful": "d"
+			}
+		},
+		"sources": [{"name": "s", "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), `llm.models.balanced.fallbacks[0]: unsupported provider "nope"`) {
+		t.Fatalf("expected fallback validation error, got %v", err)
+	}
+}
+
+func TestParse_RetryPolicies(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"retry": {
+			"llm": {"maxRetries": 4, "baseDelay": "1s", "maxDelay": "1m"},
+			"http": {"maxRetries": 0, "baseDelay": 0.25}
+		},
+		"sources": [{"name": "s", "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if cfg.Retry.LLM.MaxRetries == nil || *cfg.Retry.LLM.MaxRetries != 4 {
+		t.Fatalf("unexpected llm retries: %#v", cfg.Retry.LLM)
+	}
+	if time.Duration(cfg.Retry.LLM.MaxDelay) != time.Minute {
+		t.Fatalf("unexpected llm max delay: %s", time.Duration(cfg.Retry.LLM.MaxDelay))
+	}
+	if cfg.Retry.HTTP.MaxRetries == nil || *cfg.Retry.HTTP.MaxRetries != 0 {
+		t.Fatalf("expected explicit zero http retries, got %#v", cfg.Retry.HTTP)
+	}
+	if time.Duration(cfg.Retry.HTTP.BaseDelay) != 250*time.Millisecond {
+		t.Fatalf("unexpected http base delay: %s", time.Duration(cfg.Retry.HTTP.BaseDelay))
+	}
+
+	_, err = Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"retry": {"llm": {"baseDelay": "1m", "maxDelay": "1s"}},
+		"sources": [{"name": "s", "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), "retry.llm.baseDelay must not exceed maxDelay") {
+		t.Fatalf("expected retry validation error, got %v", err)
+	}
+}
+
+func TestParse_BudgetRequiresPricingForCost(t *testing.T) {
+	_, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [{"name": "s", "budget": {"maxCost": 1.5}, "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), "source[0].budget.maxCost requires llm.pricing") {
+		t.Fatalf("expected budget validation error, got %v", err)
+	}
+
+	cfg, err := Parse([]byte(`{
+		"llm": {
+			"provider": "qwen",
+			"models": {"fast": "a", "balanced": "b", "powerful": "c"},
+			"pricing": {"b": {"input": 0.8, "output": 2}}
+		},
+		"sources": [{"name": "s", "budget": {"maxCost": 1.5, "maxTokens": 100000}, "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if cfg.LLM.Pricing["b"].Output != 2 || cfg.Sources[0].Budget.MaxTokens != 100000 {
+		t.Fatalf("unexpected pricing or budget: %#v %#v", cfg.LLM.Pricing, cfg.Sources[0].Budget)
+	}
+}
+
+func TestParse_ValidatesSchedule(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [
+			{"name": "hourly", "schedule": "0 * * * *", "plugins": ["builtin/reporter-rss"]},
+			{"name": "weekly", "schedule": "168h", "plugins": ["builtin/reporter-rss"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if cfg.Sources[0].Schedule != "0 * * * *" || cfg.Sources[1].Schedule != "168h" {
+		t.Fatalf("unexpected schedules: %#v", cfg.Sources)
+	}
+
+	_, err = Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [{"name": "s", "schedule": "61 * * * *", "plugins": ["builtin/reporter-rss"]}]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), "source[0].schedule") {
+		t.Fatalf("expected schedule validation error, got %v", err)
+	}
+}
+
+func TestParse_RejectsReservedSourceNames(t *testing.T) {
+	_, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [
+			{"name": "serve", "plugins": ["builtin/reporter-rss"]},
+			{"name": "served", "plugins": ["builtin/reporter-rss"]}
+		]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), `source[0]: name "serve" is reserved`) {
+		t.Fatalf("expected reserved name error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "source[1]") {
+		t.Fatalf("expected only the reserved name to be rejected, got %v", err)
+	}
+}
+
+func TestParse_ValidatesOnError(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [{"name": "s", "plugins": [
+			{"name": "builtin/collect-rss", "onError": "skip"},
+			{"name": "builtin/reporter-rss", "onError": "retry:3"}
+		]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	policy, err := ParseErrorPolicy(cfg.Sources[0].Plugins[1].OnError)
+	if err != nil || policy.Retries != 3 || policy.Skip {
+		t.Fatalf("unexpected policy %#v, err %v", policy, err)
+	}
+
+	_, err = Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [{"name": "s", "plugins": [{"name": "builtin/reporter-rss", "onError": "retry:0"}]}]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), "source[0].plugins[0].onError") {
+		t.Fatalf("expected onError validation error, got %v", err)
+	}
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	cfg := Config{
+		LLM:   LLMConfig{Provider: "openai", Models: LLMModels{Fast: LLMTier{Model: "a"}, Balanced: LLMTier{Model: "b"}, Powerful: LLMTier{Model: "c"}}},
+		Cache: CacheConfig{TTL: Duration(-1)},
+		Sources: []SourceConfig{
+			{Name: "news", Plugins: []PluginEntry{{Name: "builtin/reporter-rss", OnError: "sometimes"}}},
+			{Name: "empty"},
+		},
+	}
+	err := cfg.Validate()
+	for _, want := range []string{
+		"cache.ttl must not be negative",
+		`source[0].plugins[0].onError: unsupported onError "sometimes"`,
+		"source[1]: at least one plugin is required",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q among the errors, got %v", want, err)
+		}
+	}
+}
+
+func TestParse_ParsesTimeouts(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [{"name": "s", "maxDuration": "10m", "plugins": [{"name": "builtin/llm-grade", "timeout": "2m"}]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got := time.Duration(cfg.Sources[0].MaxDuration); got != 10*time.Minute {
+		t.Fatalf("maxDuration = %s", got)
+	}
+	if got := time.Duration(cfg.Sources[0].Plugins[0].Timeout); got != 2*time.Minute {
+		t.Fatalf("timeout = %s", got)
+	}
+
+	_, err = Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [{"name": "s", "plugins": [{"name": "builtin/llm-grade", "timeout": -1}]}]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), "source[0].plugins[0].timeout") {
+		t.Fatalf("expected timeout validation error, got %v", err)
+	}
+}
+
+func TestParse_ValidatesPhasesAndPrefix(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [{"name": "s", "prefix": [], "plugins": [{"name": "builtin/clean-text", "phases": ["process"]}]}]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if cfg.Sources[0].Prefix == nil || len(cfg.Sources[0].Prefix) != 0 {
+		t.Fatalf("expected an explicit empty prefix, got %#v", cfg.Sources[0].Prefix)
+	}
+	entry := cfg.Sources[0].Plugins[0]
+	if entry.RunsIn(PhaseCollect) || !entry.RunsIn(PhaseProcess) {
+		t.Fatalf("unexpected phases: %#v", entry.Phases)
+	}
+
+	for _, tc := range []struct {
+		source string
+		want   string
+	}{
+		{`{"name": "s", "plugins": [{"name": "x", "phases": ["publish"]}]}`, `source[0].plugins[0].phases: unsupported phase "publish"`},
+		{`{"name": "s", "prefix": [{"name": "x", "phases": ["report"]}], "plugins": ["y"]}`, "source[0].prefix[0].phases"},
+	} {
+		_, err := Parse([]byte(`{
+			"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+			"sources": [` + tc.source + `]
+		}`))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("expected %q, got %v", tc.want, err)
+		}
+	}
+}
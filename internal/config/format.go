@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a config file syntax. Every format is converted to the JSON
+// document Parse reads, so the schema and validation are shared.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// FormatOf picks the format from a file extension. Anything other than
+// .yaml, .yml or .toml is read as JSON.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// decodeDocument parses data into the generic document every format is
+// normalized to before it is decoded into Config.
+func decodeDocument(data []byte, format Format) (map[string]any, error) {
+	var (
+		doc any
+		err error
+	)
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err = decoder.Decode(&doc); err == nil && decoder.More() {
+			err = errors.New("unexpected data after the top-level value")
+		}
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &doc)
+	case FormatTOML:
+		var table map[string]any
+		err = toml.Unmarshal(data, &table)
+		doc = table
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s config: %w", format, err)
+	}
+
+	doc, err = interpolate(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	switch doc := doc.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case map[string]any:
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("parse %s config: top level must be an object", format)
+	}
+}
+
+// interpolate expands ${VAR} and ${VAR:-default} in every string value of
+// doc, and turns YAML's map[any]any into objects JSON can encode. path names
+// the value in errors.
+func interpolate(doc any, path string) (any, error) {
+	switch value := doc.(type) {
+	case string:
+		expanded, err := expandEnv(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", displayPath(path), err)
+		}
+		return expanded, nil
+	case map[string]any:
+		for key, item := range value {
+			expanded, err := interpolate(item, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			value[key] = expanded
+		}
+		return value, nil
+	case map[any]any:
+		object := make(map[string]any, len(value))
+		for key, item := range value {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: keys must be strings, got %v", displayPath(path), key)
+			}
+			expanded, err := interpolate(item, joinPath(path, name))
+			if err != nil {
+				return nil, err
+			}
+			object[name] = expanded
+		}
+		return object, nil
+	case []any:
+		for i, item := range value {
+			expanded, err := interpolate(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			value[i] = expanded
+		}
+		return value, nil
+	case []map[string]any:
+		items := make([]any, len(value))
+		for i, item := range value {
+			expanded, err := interpolate(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = expanded
+		}
+		return items, nil
+	default:
+		return doc, nil
+	}
+}
+
+// expandEnv replaces ${VAR} with the variable's value and ${VAR:-default}
+// with its value or default when it is unset or empty. An unset variable
+// without a default is an error rather than an empty string. $${ is a literal
+// ${.
+func expandEnv(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var out strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			out.WriteString(s[:start-1])
+			out.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ${ in %q", s)
+		}
+		out.WriteString(s[:start])
+		expr := s[start+2 : start+end]
+		name, fallback, hasDefault := strings.Cut(expr, ":-")
+		if !validEnvName(name) {
+			return "", fmt.Errorf("invalid variable reference ${%s}", expr)
+		}
+		value, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && value == "":
+			value = fallback
+		case !ok:
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		out.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+func validEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
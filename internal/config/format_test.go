@@ -0,0 +1,204 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const formatJSON = `{
+	"llm": {
+		"provider": "openai",
+		"baseUrl": "${SIEVE_TEST_BASE_URL:-https://api.example.com/v1}",
+		"models": {"fast": "gpt-fast", "balanced": "gpt-balanced", "powerful": "gpt-powerful"}
+	},
+	"plugins": {
+		"builtin/reporter-rss": {"outputPath": "${SIEVE_TEST_OUTPUT}/news.xml"}
+	},
+	"sources": [
+		{
+			"name": "news",
+			"context": "Tech news\nwith a second line",
+			"timeout": "30s",
+			"plugins": [
+				"builtin/collect-rss",
+				{"name": "builtin/reporter-rss", "options": {"showReason": true}}
+			]
+		}
+	]
+}`
+
+const formatYAML = `
+# Same config as formatJSON.
+llm:
+  provider: openai
+  baseUrl: ${SIEVE_TEST_BASE_URL:-https://api.example.com/v1}
+  models:
+    fast: gpt-fast
+    balanced: gpt-balanced
+    powerful: gpt-powerful
+plugins:
+  builtin/reporter-rss:
+    outputPath: ${SIEVE_TEST_OUTPUT}/news.xml
+sources:
+  - name: news
+    context: |-
+      Tech news
+      with a second line
+    timeout: 30s
+    plugins:
+      - builtin/collect-rss
+      - name: builtin/reporter-rss
+        options:
+          showReason: true
+`
+
+const formatTOML = `
+# Same config as formatJSON.
+[llm]
+provider = "openai"
+baseUrl = "${SIEVE_TEST_BASE_URL:-https://api.example.com/v1}"
+
+[llm.models]
+fast = "gpt-fast"
+balanced = "gpt-balanced"
+powerful = "gpt-powerful"
+
+[plugins."builtin/reporter-rss"]
+outputPath = "${SIEVE_TEST_OUTPUT}/news.xml"
+
+[[sources]]
+name = "news"
+context = """
+Tech news
+with a second line"""
+timeout = "30s"
+plugins = [
+  "builtin/collect-rss",
+  { name = "builtin/reporter-rss", options = { showReason = true } },
+]
+`
+
+func TestLoad_PicksFormatFromExtension(t *testing.T) {
+	t.Setenv("SIEVE_TEST_OUTPUT", "/srv/feeds")
+	dir := t.TempDir()
+	var configs []*Config
+	for name, data := range map[string]string{"config.json": formatJSON, "config.yaml": formatYAML, "config.yml": formatYAML, "config.toml": formatTOML} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load(%s): %v", name, err)
+		}
+		configs = append(configs, cfg)
+	}
+
+	want := configs[0]
+	if want.LLM.BaseURL != "https://api.example.com/v1" {
+		t.Fatalf("expected default base URL, got %q", want.LLM.BaseURL)
+	}
+	var options struct{ OutputPath string }
+	if err := json.Unmarshal(want.Plugins["builtin/reporter-rss"], &options); err != nil {
+		t.Fatal(err)
+	}
+	if options.OutputPath != "/srv/feeds/news.xml" {
+		t.Fatalf("expected interpolated output path, got %q", options.OutputPath)
+	}
+	if want.Sources[0].Context != "Tech news\nwith a second line" {
+		t.Fatalf("unexpected context %q", want.Sources[0].Context)
+	}
+	for _, cfg := range configs[1:] {
+		if !reflect.DeepEqual(normalizeOptions(t, cfg), normalizeOptions(t, want)) {
+			t.Fatalf("configs differ:\n%+v\n%+v", cfg, want)
+		}
+	}
+}
+
+// normalizeOptions re-encodes raw plugin options so formatting differences
+// between the source formats do not matter, and drops the file names.
+func normalizeOptions(t *testing.T, cfg *Config) *Config {
+	t.Helper()
+	out := *cfg
+	out.Files = nil
+	out.Plugins = map[string]json.RawMessage{}
+	for name, raw := range cfg.Plugins {
+		out.Plugins[name] = compact(t, raw)
+	}
+	out.Sources = nil
+	for _, source := range cfg.Sources {
+		source.Plugins = append([]PluginEntry(nil), source.Plugins...)
+		for i := range source.Plugins {
+			source.Plugins[i].Options = compact(t, source.Plugins[i].Options)
+		}
+		out.Sources = append(out.Sources, source)
+	}
+	return &out
+}
+
+func compact(t *testing.T, raw json.RawMessage) json.RawMessage {
+	t.Helper()
+	if raw == nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse_InterpolatesEnvironment(t *testing.T) {
+	t.Setenv("SIEVE_TEST_SET", "value")
+	t.Setenv("SIEVE_TEST_EMPTY", "")
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "${SIEVE_TEST_SET}", want: "value"},
+		{in: "a-${SIEVE_TEST_SET}-b-${SIEVE_TEST_SET}", want: "a-value-b-value"},
+		{in: "${SIEVE_TEST_EMPTY:-fallback}", want: "fallback"},
+		{in: "${SIEVE_TEST_UNSET:-}", want: ""},
+		{in: "${SIEVE_TEST_EMPTY}", want: ""},
+		{in: "$${SIEVE_TEST_SET}", want: "${SIEVE_TEST_SET}"},
+		{in: "no variables", want: "no variables"},
+		{in: "${SIEVE_TEST_UNSET}", wantErr: "sources[0].context: environment variable SIEVE_TEST_UNSET is not set"},
+		{in: "${SIEVE_TEST_SET", wantErr: "unterminated ${"},
+		{in: "${1BAD}", wantErr: "invalid variable reference ${1BAD}"},
+	}
+	for _, tt := range tests {
+		cfg, err := Parse([]byte(`{
+			"llm": {"provider": "openai", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+			"sources": [{"name": "news", "context": "` + tt.in + `", "plugins": ["builtin/collect-rss"]}]
+		}`))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("%q: expected error containing %q, got %v", tt.in, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.in, err)
+		}
+		if got := cfg.Sources[0].Context; got != tt.want {
+			t.Fatalf("%q: expected %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestParse_RejectsTrailingData(t *testing.T) {
+	_, err := Parse([]byte(`{"llm": {"provider": "openai"}} {}`))
+	if err == nil || !strings.Contains(err.Error(), "unexpected data") {
+		t.Fatalf("expected trailing data error, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// includedKeys are the top-level keys an included file may set. Everything
+// else (llm, retry, cache) is shared from the main config.
+var includedKeys = []string{"include", "plugins", "sources"}
+
+// loader merges a config and the files it includes into one document.
+// Sources are appended in include order and plugin defaults are merged;
+// defining either twice is an error naming both files.
+type loader struct {
+	root    map[string]any
+	files   []string
+	stack   []string
+	seen    map[string]bool
+	sources map[string]string
+	plugins map[string]string
+}
+
+func load(path string, data []byte, format Format) (*Config, error) {
+	root, err := decodeDocument(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		root = map[string]any{}
+	}
+	name, dir := path, filepath.Dir(path)
+	if path == "" {
+		name, dir = "config", "."
+	}
+	l := &loader{
+		root:    root,
+		seen:    map[string]bool{},
+		sources: map[string]string{},
+		plugins: map[string]string{},
+	}
+	if path != "" {
+		l.files = append(l.files, path)
+		if abs, err := filepath.Abs(path); err == nil {
+			l.stack = append(l.stack, abs)
+			l.seen[abs] = true
+		}
+	}
+	if err := l.register(root, name); err != nil {
+		return nil, err
+	}
+	if err := l.include(root, name, dir); err != nil {
+		return nil, err
+	}
+
+	normalized, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s config: %w", format, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(normalized, &cfg); err != nil {
+		return nil, err
+	}
+	cfg.Files = l.files
+	return &cfg, nil
+}
+
+// include merges every file doc includes into the root document, depth
+// first. name and dir identify the file doc came from.
+func (l *loader) include(doc map[string]any, name string, dir string) error {
+	raw, ok := doc["include"]
+	if !ok {
+		return nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return fmt.Errorf("%s: include must be a list of paths", name)
+	}
+	for _, item := range list {
+		pattern, ok := item.(string)
+		if !ok {
+			return fmt.Errorf("%s: include must be a list of paths", name)
+		}
+		paths, err := includePaths(dir, pattern)
+		if err != nil {
+			return fmt.Errorf("%s: include %q: %w", name, pattern, err)
+		}
+		for _, path := range paths {
+			if err := l.includeFile(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *loader) includeFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if i := slices.Index(l.stack, abs); i >= 0 {
+		return fmt.Errorf("include cycle: %s -> %s", strings.Join(l.stack[i:], " -> "), abs)
+	}
+	if l.seen[abs] {
+		return nil
+	}
+	l.seen[abs] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeDocument(data, FormatOf(path))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for key := range doc {
+		if !slices.Contains(includedKeys, key) {
+			return fmt.Errorf("%s: %q can only be set in the main config", path, key)
+		}
+	}
+	if err := l.register(doc, path); err != nil {
+		return err
+	}
+	l.files = append(l.files, path)
+
+	if plugins, ok := doc["plugins"].(map[string]any); ok {
+		merged, _ := l.root["plugins"].(map[string]any)
+		if merged == nil {
+			merged = map[string]any{}
+			l.root["plugins"] = merged
+		}
+		for name, options := range plugins {
+			merged[name] = options
+		}
+	}
+	if sources, ok := doc["sources"].([]any); ok {
+		merged, _ := l.root["sources"].([]any)
+		l.root["sources"] = append(merged, sources...)
+	}
+
+	l.stack = append(l.stack, abs)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+	return l.include(doc, path, filepath.Dir(path))
+}
+
+// register records which file defines each source and plugin default, and
+// rejects ones already defined.
+func (l *loader) register(doc map[string]any, file string) error {
+	if raw, ok := doc["plugins"]; ok {
+		plugins, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: plugins must be an object", file)
+		}
+		for name := range plugins {
+			if prev, ok := l.plugins[name]; ok {
+				return fmt.Errorf("plugins[%q] is set in both %s and %s", name, prev, file)
+			}
+			l.plugins[name] = file
+		}
+	}
+	if raw, ok := doc["sources"]; ok {
+		sources, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("%s: sources must be a list", file)
+		}
+		for _, source := range sources {
+			fields, _ := source.(map[string]any)
+			name, _ := fields["name"].(string)
+			if name == "" {
+				continue
+			}
+			switch prev, ok := l.sources[name]; {
+			case ok && prev == file:
+				return fmt.Errorf("source %q is defined twice in %s", name, file)
+			case ok:
+				return fmt.Errorf("source %q is defined in both %s and %s", name, prev, file)
+			}
+			l.sources[name] = file
+		}
+	}
+	return nil
+}
+
+// includePaths resolves one include entry: a file, a directory whose config
+// files are all included in name order, or a glob. A glob that matches
+// nothing is not an error, so an empty sources.d/*.yaml is fine.
+func includePaths(dir string, pattern string) ([]string, error) {
+	path := pattern
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var paths []string
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || !isConfigFile(entry.Name()) {
+				continue
+			}
+			paths = append(paths, filepath.Join(path, entry.Name()))
+		}
+		return paths, nil
+	}
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+		return nil, fmt.Errorf("no such file %s", path)
+	}
+	var paths []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			paths = append(paths, match)
+		}
+	}
+	return paths, nil
+}
+
+func isConfigFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
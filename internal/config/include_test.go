@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const includeMain = `{
+	"llm": {"provider": "openai", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+	"include": ["shared.yaml", "sources.d"],
+	"plugins": {"builtin/reporter-rss": {"showReason": true}},
+	"sources": [{"name": "main", "plugins": ["builtin/collect-rss"]}]
+}`
+
+func TestLoad_MergesIncludedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.json": includeMain,
+		"shared.yaml": "plugins:\n  builtin/llm-grade:\n    batchSize: 10\n",
+		"sources.d/b-zhihu.toml": `
+[[sources]]
+name = "zhihu"
+plugins = ["zhihu"]
+`,
+		"sources.d/a-news.yaml": `
+include: [../extra/*.json]
+sources:
+  - name: news
+    plugins: [builtin/collect-rss]
+`,
+		"sources.d/notes.txt":   "ignored",
+		"extra/hn.json":         `{"sources": [{"name": "hacker-news", "plugins": ["hacker-news"]}]}`,
+		"sources.d/.hidden.yml": "sources: [{name: hidden, plugins: [x]}]",
+	})
+
+	cfg, err := Load(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var names []string
+	for _, source := range cfg.Sources {
+		names = append(names, source.Name)
+	}
+	if want := []string{"main", "news", "hacker-news", "zhihu"}; !slices.Equal(names, want) {
+		t.Fatalf("expected sources %v, got %v", want, names)
+	}
+	if _, ok := cfg.Plugins["builtin/llm-grade"]; !ok {
+		t.Fatalf("expected shared plugin defaults to be merged, got %v", cfg.Plugins)
+	}
+	var files []string
+	for _, file := range cfg.Files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	if want := []string{"config.json", "shared.yaml", "sources.d/a-news.yaml", "extra/hn.json", "sources.d/b-zhihu.toml"}; !slices.Equal(files, want) {
+		t.Fatalf("expected files %v, got %v", want, files)
+	}
+	if !slices.Equal(cfg.Include, []string{"shared.yaml", "sources.d"}) {
+		t.Fatalf("unexpected include list %v", cfg.Include)
+	}
+}
+
+func TestLoad_RejectsInvalidIncludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "duplicate source across files",
+			files: map[string]string{
+				"sources.d/a.json": `{"sources": [{"name": "main", "plugins": ["x"]}]}`,
+			},
+			wantErr: `source "main" is defined in both ` + "%DIR%/config.json and %DIR%/sources.d/a.json",
+		},
+		{
+			name: "duplicate plugin defaults",
+			files: map[string]string{
+				"shared.yaml": "plugins:\n  builtin/reporter-rss: {}\n",
+			},
+			wantErr: `plugins["builtin/reporter-rss"] is set in both`,
+		},
+		{
+			name: "llm outside the main config",
+			files: map[string]string{
+				"shared.yaml": "llm:\n  provider: qwen\n",
+			},
+			wantErr: `"llm" can only be set in the main config`,
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"sources.d/a.json": `{"include": ["missing.json"]}`,
+			},
+			wantErr: `include "missing.json": no such file`,
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"sources.d/a.json": `{"include": ["b.json"]}`,
+				"sources.d/b.json": `{"include": ["a.json"]}`,
+			},
+			wantErr: "include cycle",
+		},
+		{
+			name: "interpolation error names the file",
+			files: map[string]string{
+				"sources.d/a.yaml": "sources:\n  - name: ${SIEVE_TEST_UNSET}\n    plugins: [x]\n",
+			},
+			wantErr: "sources.d/a.yaml: sources[0].name: environment variable SIEVE_TEST_UNSET is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{"config.json": includeMain, "shared.yaml": "{}"}
+			if err := os.MkdirAll(filepath.Join(dir, "sources.d"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, data := range tt.files {
+				files[name] = data
+			}
+			writeFiles(t, dir, files)
+
+			_, err := Load(filepath.Join(dir, "config.json"))
+			want := strings.ReplaceAll(tt.wantErr, "%DIR%", dir)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Fatalf("expected error containing %q, got %v", want, err)
+			}
+		})
+	}
+}
+
+func TestParse_RejectsDuplicateSourceNames(t *testing.T) {
+	_, err := Parse([]byte(`{
+		"llm": {"provider": "openai", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"sources": [
+			{"name": "news", "plugins": ["x"]},
+			{"name": "news", "plugins": ["y"]}
+		]
+	}`))
+	if err == nil || !strings.Contains(err.Error(), `source "news" is defined twice in config`) {
+		t.Fatalf("expected duplicate source error, got %v", err)
+	}
+
+	cfg := Config{
+		LLM:     LLMConfig{Provider: "openai", Models: LLMModels{Fast: LLMTier{Model: "a"}, Balanced: LLMTier{Model: "b"}, Powerful: LLMTier{Model: "c"}}},
+		Sources: []SourceConfig{{Name: "news", Plugins: []PluginEntry{{Name: "x"}}}, {Name: "news", Plugins: []PluginEntry{{Name: "y"}}}},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `duplicate name "news"`) {
+		t.Fatalf("expected Validate to reject duplicate names, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// appendSuffix marks an option key whose array value is appended to the
+// inherited array instead of replacing it, as in "interest+": ["Rust"].
+const appendSuffix = "+"
+
+// MergeOptions merges a plugin entry's options over the global defaults for
+// that plugin. Objects merge key by key, recursively; any other value,
+// arrays included, replaces the inherited one. A key ending in "+" appends
+// its array to the inherited array, or to an inherited string taken as a
+// one-element list, instead. Both sides must be JSON objects.
+func MergeOptions(global json.RawMessage, local json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(global)) == 0 && len(bytes.TrimSpace(local)) == 0 {
+		return nil, nil
+	}
+	base, err := decodeOptions(global)
+	if err != nil {
+		return nil, fmt.Errorf("global %w", err)
+	}
+	over, err := decodeOptions(local)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergeObjects(nil, base, "")
+	if err != nil {
+		return nil, fmt.Errorf("global options: %w", err)
+	}
+	if merged, err = mergeObjects(merged, over, ""); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+func decodeOptions(raw json.RawMessage) (map[string]any, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("options are not valid JSON: %w", err)
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		return value, nil
+	case []any:
+		return nil, errors.New("options must be an object, got array")
+	case string:
+		return nil, errors.New("options must be an object, got string")
+	case json.Number:
+		return nil, errors.New("options must be an object, got number")
+	case bool:
+		return nil, errors.New("options must be an object, got bool")
+	default:
+		return nil, nil
+	}
+}
+
+// mergeObjects applies over on top of base. path names the object in errors.
+func mergeObjects(base map[string]any, over map[string]any, path string) (map[string]any, error) {
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	for _, key := range slices.Sorted(maps.Keys(over)) {
+		value := over[key]
+		name, appending := strings.CutSuffix(key, appendSuffix)
+		if appending {
+			if _, ok := over[name]; ok {
+				return nil, fmt.Errorf("option %q: cannot set both %q and %q", path+name, name, key)
+			}
+			items, ok := value.([]any)
+			if !ok {
+				return nil, fmt.Errorf("option %q: only arrays can be appended to", path+key)
+			}
+			switch inherited := merged[name].(type) {
+			case nil:
+				merged[name] = items
+			case []any:
+				merged[name] = append(slices.Clone(inherited), items...)
+			case string:
+				// A string is the one-element form of a StringList, such as
+				// a comma-separated list of topics.
+				if inherited != "" {
+					items = append([]any{inherited}, items...)
+				}
+				merged[name] = items
+			default:
+				return nil, fmt.Errorf("option %q: cannot append to a non-array value", path+key)
+			}
+			continue
+		}
+		overObject, overIsObject := value.(map[string]any)
+		baseObject, baseIsObject := merged[key].(map[string]any)
+		if overIsObject {
+			var err error
+			if !baseIsObject {
+				baseObject = nil
+			}
+			if merged[key], err = mergeObjects(baseObject, overObject, path+key+"."); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		merged[key] = value
+	}
+	return merged, nil
+}
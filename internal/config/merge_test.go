@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMergeOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		global  string
+		local   string
+		want    string
+		wantErr string
+	}{
+		{name: "both empty", want: ""},
+		{name: "global only", global: `{"a": 1}`, want: `{"a":1}`},
+		{name: "local only", local: `{"a": 1}`, want: `{"a":1}`},
+		{name: "local wins", global: `{"a": 1, "b": 2}`, local: `{"b": 3}`, want: `{"a":1,"b":3}`},
+		{
+			name:   "objects merge recursively",
+			global: `{"headers": {"User-Agent": "sieve", "Accept": "text/html"}, "limit": 5}`,
+			local:  `{"headers": {"Accept": "application/json", "X-Token": "t"}}`,
+			want:   `{"headers":{"Accept":"application/json","User-Agent":"sieve","X-Token":"t"},"limit":5}`,
+		},
+		{name: "arrays replace by default", global: `{"topics": ["a", "b"]}`, local: `{"topics": ["c"]}`, want: `{"topics":["c"]}`},
+		{name: "arrays append with +", global: `{"topics": ["a", "b"]}`, local: `{"topics+": ["c"]}`, want: `{"topics":["a","b","c"]}`},
+		{name: "append without a global value", local: `{"topics+": ["c"]}`, want: `{"topics":["c"]}`},
+		{name: "append in a nested object", global: `{"grade": {"topics": ["a"]}}`, local: `{"grade": {"topics+": ["b"]}}`, want: `{"grade":{"topics":["a","b"]}}`},
+		{name: "append directive in global options", global: `{"topics+": ["a"]}`, want: `{"topics":["a"]}`},
+		{name: "scalar replaces object", global: `{"a": {"b": 1}}`, local: `{"a": 2}`, want: `{"a":2}`},
+		{name: "large integers survive", global: `{"id": 9007199254740993}`, want: `{"id":9007199254740993}`},
+		{name: "null options are empty", global: `{"a": 1}`, local: `null`, want: `{"a":1}`},
+		{name: "append to a string", global: `{"topics": "a,b"}`, local: `{"topics+": ["c"]}`, want: `{"topics":["a,b","c"]}`},
+		{name: "append to an empty string", global: `{"topics": ""}`, local: `{"topics+": ["c"]}`, want: `{"topics":["c"]}`},
+		{name: "append to a number", global: `{"topics": 1}`, local: `{"topics+": ["c"]}`, wantErr: `option "topics+": cannot append to a non-array value`},
+		{name: "append a non-array", global: `{"topics": ["a"]}`, local: `{"topics+": "c"}`, wantErr: `option "topics+": only arrays can be appended to`},
+		{name: "set and append the same key", local: `{"topics": ["a"], "topics+": ["b"]}`, wantErr: `cannot set both "topics" and "topics+"`},
+		{name: "local not an object", global: `{"a": 1}`, local: `["a"]`, wantErr: "options must be an object, got array"},
+		{name: "global not an object", global: `"a"`, local: `{"a": 1}`, wantErr: "global options must be an object, got string"},
+		{name: "invalid JSON", global: `{"a": 1}`, local: `{"a": }`, wantErr: "options are not valid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeOptions(json.RawMessage(tt.global), json.RawMessage(tt.local))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v (%s)", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStringList_AcceptsStringOrArray(t *testing.T) {
+	var options struct {
+		Topics StringList `json:"topics"`
+	}
+	if err := json.Unmarshal([]byte(`{"topics": "Rust,Linux"}`), &options); err != nil {
+		t.Fatal(err)
+	}
+	if options.Topics.String() != "Rust,Linux" {
+		t.Fatalf("unexpected list %q", options.Topics)
+	}
+	if err := json.Unmarshal([]byte(`{"topics": ["Rust", "Linux", "KDE"]}`), &options); err != nil {
+		t.Fatal(err)
+	}
+	if options.Topics.String() != "Rust,Linux,KDE" {
+		t.Fatalf("unexpected list %q", options.Topics)
+	}
+}
+
+func TestParse_ValidatesMergedPluginOptions(t *testing.T) {
+	RegisterOptions("test/grade", struct {
+		Interest StringList `json:"interest"`
+		Limit    int        `json:"limit"`
+	}{})
+
+	cfg, err := Parse([]byte(`{
+		"llm": {"provider": "openai", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"plugins": {"test/grade": {"interest": ["Rust"]}},
+		"sources": [{"name": "news", "plugins": [{"name": "test/grade", "options": {"interest+": ["Linux"]}}]}]
+	}`))
+	if err != nil {
+		t.Fatalf("expected append directive to validate, got %v", err)
+	}
+	if len(cfg.Sources) != 1 {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if _, err := Parse([]byte(`{
+		"llm": {"provider": "openai", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"plugins": {"test/grade": {"interest": "Rust,Go"}},
+		"sources": [{"name": "news", "plugins": [{"name": "test/grade", "options": {"interest+": ["Linux"]}}]}]
+	}`)); err != nil {
+		t.Fatalf("expected appending to a comma-separated default to validate, got %v", err)
+	}
+
+	for _, tt := range []struct{ options, wantErr string }{
+		{`{"interest+": "Linux"}`, `source "news": plugin "test/grade": option "interest+": only arrays can be appended to`},
+		{`{"limitt+": [1]}`, `source "news": plugin "test/grade": unknown option "limitt"`},
+		{`{"interest": 5}`, `source "news": plugin "test/grade": option "interest": expected string or array of strings, got number`},
+	} {
+		_, err := Parse([]byte(`{
+			"llm": {"provider": "openai", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+			"plugins": {"test/grade": {"interest": ["Rust"]}},
+			"sources": [{"name": "news", "plugins": [{"name": "test/grade", "options": ` + tt.options + `}]}]
+		}`))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Fatalf("%s: expected error containing %q, got %v", tt.options, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// OptionsSchema describes the options a plugin accepts. It is built from the
+// struct the plugin decodes its options into, using the json tags as keys.
+type OptionsSchema struct {
+	typ reflect.Type
+}
+
+// OptionField is one option key. Required and Path come from the field's
+// `option` tag: `option:"required"` marks a key that must be set, and
+// `option:"path"` a file the plugin writes.
+type OptionField struct {
+	Name     string
+	Type     string
+	Required bool
+	Path     bool
+}
+
+var (
+	optionSchemas   = map[string]OptionsSchema{}
+	optionSchemasMu sync.RWMutex
+)
+
+// RegisterOptions records the options struct for a plugin so Validate can
+// check every entry that uses it. options is a value of that struct type.
+func RegisterOptions(plugin string, options any) {
+	typ := reflect.TypeOf(options)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("options for plugin %q must be a struct, got %T", plugin, options))
+	}
+	optionSchemasMu.Lock()
+	defer optionSchemasMu.Unlock()
+	optionSchemas[plugin] = OptionsSchema{typ: typ}
+}
+
+func LookupOptions(plugin string) (OptionsSchema, bool) {
+	optionSchemasMu.RLock()
+	defer optionSchemasMu.RUnlock()
+	schema, ok := optionSchemas[plugin]
+	return schema, ok
+}
+
+func (s OptionsSchema) Fields() []OptionField {
+	fields := make([]OptionField, 0, s.typ.NumField())
+	for i := range s.typ.NumField() {
+		field := s.typ.Field(i)
+		name, ok := optionName(field)
+		if !ok {
+			continue
+		}
+		flags := strings.Split(field.Tag.Get("option"), ",")
+		fields = append(fields, OptionField{
+			Name:     name,
+			Type:     typeName(field.Type),
+			Required: slices.Contains(flags, "required"),
+			Path:     slices.Contains(flags, "path"),
+		})
+	}
+	return fields
+}
+
+// Validate checks options against the schema's struct. Keys must match a
+// json tag exactly; encoding/json alone would accept "maxitems" for
+// "maxItems".
+func (s OptionsSchema) Validate(options json.RawMessage) error {
+	if len(bytes.TrimSpace(options)) == 0 {
+		return nil
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(options, &keys); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("options must be an object, got %s", typeErr.Value)
+		}
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	known := map[string]struct{}{}
+	for _, field := range s.Fields() {
+		known[field.Name] = struct{}{}
+	}
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if _, ok := known[key]; !ok {
+			return fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	err := json.Unmarshal(options, reflect.New(s.typ).Interface())
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := typeErr.Field
+		if field == "" {
+			field = s.failingKey(keys)
+		}
+		return fmt.Errorf("option %q: expected %s, got %s", field, typeName(typeErr.Type), typeErr.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
+
+// failingKey finds the key whose value does not decode. encoding/json leaves
+// the field name empty for errors returned by a field's own UnmarshalJSON.
+func (s OptionsSchema) failingKey(keys map[string]json.RawMessage) string {
+	for i := range s.typ.NumField() {
+		field := s.typ.Field(i)
+		name, ok := optionName(field)
+		if !ok {
+			continue
+		}
+		raw, ok := keys[name]
+		if ok && json.Unmarshal(raw, reflect.New(field.Type).Interface()) != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// Missing returns the required options that are absent, null or empty in
+// options.
+func (s OptionsSchema) Missing(options json.RawMessage) ([]string, error) {
+	var values map[string]any
+	if len(bytes.TrimSpace(options)) > 0 {
+		if err := json.Unmarshal(options, &values); err != nil {
+			return nil, err
+		}
+	}
+	var missing []string
+	for _, field := range s.Fields() {
+		if !field.Required {
+			continue
+		}
+		if value, ok := values[field.Name]; !ok || value == nil || value == "" {
+			missing = append(missing, field.Name)
+		}
+	}
+	return missing, nil
+}
+
+func validateOptions(plugin string, options json.RawMessage) error {
+	schema, ok := LookupOptions(plugin)
+	if !ok {
+		return nil
+	}
+	return schema.Validate(options)
+}
+
+func optionName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func typeName(typ reflect.Type) string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case reflect.TypeFor[Duration]():
+		return "duration"
+	case reflect.TypeFor[StringList]():
+		return "string or array of strings"
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array of " + typeName(typ.Elem())
+	default:
+		return "object"
+	}
+}
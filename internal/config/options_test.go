@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testCollectOptions struct {
+	URL      string    `json:"url"`
+	MaxItems int       `json:"maxItems"`
+	Tags     []string  `json:"tags,omitempty"`
+	Timeout  *Duration `json:"timeout,omitempty"`
+	internal bool
+}
+
+func TestOptionsSchema_DescribesFields(t *testing.T) {
+	RegisterOptions("test/collect", testCollectOptions{})
+	schema, ok := LookupOptions("test/collect")
+	if !ok {
+		t.Fatal("expected registered schema")
+	}
+	want := []OptionField{
+		{Name: "url", Type: "string"},
+		{Name: "maxItems", Type: "integer"},
+		{Name: "tags", Type: "array of string"},
+		{Name: "timeout", Type: "duration"},
+	}
+	if got := schema.Fields(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Fields() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParse_RejectsInvalidPluginOptions(t *testing.T) {
+	RegisterOptions("test/collect", testCollectOptions{})
+	for _, tc := range []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "unknown key",
+			config: `"sources": [{"name": "news", "plugins": [{"name": "test/collect", "options": {"url": "https://example.com", "maxitems": 5}}]}]`,
+			want:   `source "news": plugin "test/collect": unknown option "maxitems"`,
+		},
+		{
+			name:   "wrong type",
+			config: `"sources": [{"name": "news", "plugins": [{"name": "test/collect", "options": {"maxItems": "5"}}]}]`,
+			want:   `source "news": plugin "test/collect": option "maxItems": expected integer, got string`,
+		},
+		{
+			name:   "global options",
+			config: `"plugins": {"test/collect": {"url": 1}}, "sources": [{"name": "news", "plugins": ["test/collect"]}]`,
+			want:   `plugins["test/collect"]: option "url": expected string, got number`,
+		},
+		{
+			name:   "not an object",
+			config: `"sources": [{"name": "news", "prefix": [{"name": "test/collect", "options": [1]}], "plugins": ["x"]}]`,
+			want:   `source "news": plugin "test/collect": options must be an object, got array`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(`{
+				"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+				` + tc.config + `
+			}`))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte(`{
+		"llm": {"provider": "qwen", "models": {"fast": "a", "balanced": "b", "powerful": "c"}},
+		"plugins": {"test/collect": {"maxItems": 20}},
+		"sources": [{"name": "news", "plugins": [{"name": "test/collect", "options": {"url": "https://example.com", "timeout": "5s"}}, "unregistered"]}]
+	}`)); err != nil {
+		t.Fatalf("expected valid options to pass, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/schedule"
+)
+
+const (
+	DefaultPollInterval    = 5 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// Daemon runs each scheduled source in-process. A source never overlaps
+// with itself, the config is reloaded when it or a file it includes changes,
+// and cancelling the context stops scheduling and waits for in-flight runs.
+type Daemon struct {
+	ConfigPath      string
+	Load            func(path string) (*config.Config, error)
+	RunSource       func(ctx context.Context, cfg *config.Config, source config.SourceConfig) error
+	Jitter          time.Duration
+	PollInterval    time.Duration
+	ShutdownTimeout time.Duration
+	Logger          *slog.Logger
+
+	now   func() time.Time
+	parse func(string) (schedule.Schedule, error)
+}
+
+type job struct {
+	source   config.SourceConfig
+	schedule schedule.Schedule
+	next     time.Time
+}
+
+type fileStamp struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+func (d *Daemon) Run(ctx context.Context) error {
+	if d.now == nil {
+		d.now = time.Now
+	}
+	if d.parse == nil {
+		d.parse = schedule.Parse
+	}
+	pollInterval := d.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	cfg, err := d.Load(d.ConfigPath)
+	if err != nil {
+		return err
+	}
+	stamp := d.stat(cfg)
+	jobs := d.plan(cfg, nil)
+
+	runCtx, cancelRuns := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRuns()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	running := map[string]bool{}
+
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	for {
+		timer := time.NewTimer(d.untilNext(jobs))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.shutdown(&wg, cancelRuns)
+			return nil
+		case <-poll.C:
+			timer.Stop()
+			next := d.stat(cfg)
+			if slices.Equal(next, stamp) {
+				continue
+			}
+			stamp = next
+			reloaded, err := d.Load(d.ConfigPath)
+			if err != nil {
+				d.logger().Error("config reload failed, keeping previous config", "config", d.ConfigPath, "error", err)
+				continue
+			}
+			cfg = reloaded
+			jobs = d.plan(cfg, jobs)
+			d.logger().Info("config reloaded", "config", d.ConfigPath, "scheduled", len(jobs))
+		case <-timer.C:
+			now := d.now()
+			for name, j := range jobs {
+				if j.next.IsZero() || j.next.After(now) {
+					continue
+				}
+				j.next = d.nextRun(j.schedule, now)
+				mu.Lock()
+				busy := running[name]
+				running[name] = true
+				mu.Unlock()
+				if busy {
+					d.logger().Warn("previous run still in progress, skipping", "source", name, "next", j.next)
+					continue
+				}
+				wg.Add(1)
+				go func(cfg *config.Config, source config.SourceConfig) {
+					defer wg.Done()
+					defer func() {
+						mu.Lock()
+						delete(running, source.Name)
+						mu.Unlock()
+					}()
+					start := d.now()
+					if err := d.RunSource(runCtx, cfg, source); err != nil {
+						d.logger().Error("scheduled run failed", "source", source.Name, "duration", d.now().Sub(start), "error", err)
+						return
+					}
+					d.logger().Info("scheduled run completed", "source", source.Name, "duration", d.now().Sub(start))
+				}(cfg, j.source)
+			}
+		}
+	}
+}
+
+// plan builds the job table for cfg. Sources whose schedule is unchanged keep
+// their pending activation so a reload does not reset them.
+func (d *Daemon) plan(cfg *config.Config, prev map[string]*job) map[string]*job {
+	jobs := make(map[string]*job, len(cfg.Sources))
+	now := d.now()
+	for _, source := range cfg.Sources {
+		if source.Schedule == "" {
+			continue
+		}
+		sched, err := d.parse(source.Schedule)
+		if err != nil {
+			d.logger().Error("invalid schedule", "source", source.Name, "error", err)
+			continue
+		}
+		if old, ok := prev[source.Name]; ok && old.source.Schedule == source.Schedule {
+			jobs[source.Name] = &job{source: source, schedule: sched, next: old.next}
+			continue
+		}
+		next := d.nextRun(sched, now)
+		jobs[source.Name] = &job{source: source, schedule: sched, next: next}
+		d.logger().Info("source scheduled", "source", source.Name, "schedule", source.Schedule, "next", next)
+	}
+	if len(jobs) == 0 {
+		d.logger().Warn("no sources have a schedule", "config", d.ConfigPath)
+	}
+	return jobs
+}
+
+func (d *Daemon) nextRun(sched schedule.Schedule, now time.Time) time.Time {
+	next := sched.Next(now)
+	if next.IsZero() || d.Jitter <= 0 {
+		return next
+	}
+	return next.Add(rand.N(d.Jitter))
+}
+
+func (d *Daemon) untilNext(jobs map[string]*job) time.Duration {
+	wait := time.Hour
+	now := d.now()
+	for _, j := range jobs {
+		if j.next.IsZero() {
+			continue
+		}
+		wait = min(wait, j.next.Sub(now))
+	}
+	return max(wait, 0)
+}
+
+func (d *Daemon) shutdown(wg *sync.WaitGroup, cancelRuns context.CancelFunc) {
+	timeout := d.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	d.logger().Info("shutting down, waiting for running sources", "timeout", timeout)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		d.logger().Warn("shutdown timeout reached, cancelling running sources")
+		cancelRuns()
+		<-done
+	}
+	d.logger().Info("daemon stopped")
+}
+
+// stat stamps every file cfg was loaded from, and the directories of
+// included files so that adding a file to an included directory counts as a
+// change.
+func (d *Daemon) stat(cfg *config.Config) []fileStamp {
+	paths := []string{d.ConfigPath}
+	if len(cfg.Files) > 0 {
+		paths = slices.Clone(cfg.Files)
+		for _, file := range cfg.Files[1:] {
+			if dir := filepath.Dir(file); !slices.Contains(paths, dir) {
+				paths = append(paths, dir)
+			}
+		}
+	}
+	stamps := make([]fileStamp, 0, len(paths))
+	for _, path := range paths {
+		stamp := fileStamp{path: path}
+		if info, err := os.Stat(path); err == nil {
+			stamp.modTime, stamp.size = info.ModTime(), info.Size()
+		}
+		stamps = append(stamps, stamp)
+	}
+	return stamps
+}
+
+func (d *Daemon) logger() *slog.Logger {
+	if d.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return d.Logger
+}
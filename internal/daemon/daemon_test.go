@@ -0,0 +1,235 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/schedule"
+)
+
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+func testParse(spec string) (schedule.Schedule, error) {
+	d, err := time.ParseDuration(spec)
+	if err != nil {
+		return nil, err
+	}
+	return everySchedule(d), nil
+}
+
+func writeConfig(t *testing.T, path string, sources ...config.SourceConfig) {
+	t.Helper()
+	data := `{"sources":[`
+	for i, source := range sources {
+		if i > 0 {
+			data += ","
+		}
+		data += `{"name":"` + source.Name + `","schedule":"` + source.Schedule + `"}`
+	}
+	data += `]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func loadTestConfig(path string) (*config.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func TestDaemon_DoesNotOverlapRunsOfTheSameSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, config.SourceConfig{Name: "slow", Schedule: "10ms"})
+
+	var active, peak, runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Daemon{
+		ConfigPath: path,
+		Load:       loadTestConfig,
+		RunSource: func(context.Context, *config.Config, config.SourceConfig) error {
+			current := active.Add(1)
+			defer active.Add(-1)
+			if current > peak.Load() {
+				peak.Store(current)
+			}
+			if runs.Add(1) == 3 {
+				cancel()
+			}
+			time.Sleep(35 * time.Millisecond)
+			return nil
+		},
+		PollInterval: time.Hour,
+		parse:        testParse,
+	}
+	if err := d.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if peak.Load() != 1 {
+		t.Fatalf("expected runs of one source never to overlap, got %d concurrent", peak.Load())
+	}
+	if active.Load() != 0 {
+		t.Fatal("expected shutdown to wait for the running source")
+	}
+}
+
+func TestDaemon_ReloadsConfigWhenFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, config.SourceConfig{Name: "first", Schedule: "10ms"})
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	reloaded := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := &Daemon{
+		ConfigPath: path,
+		Load:       loadTestConfig,
+		RunSource: func(_ context.Context, _ *config.Config, source config.SourceConfig) error {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[source.Name]++
+			if source.Name == "first" && seen["first"] == 1 {
+				close(reloaded)
+			}
+			if source.Name == "second" && seen["second"] == 1 {
+				cancel()
+			}
+			return nil
+		},
+		PollInterval: 5 * time.Millisecond,
+		parse:        testParse,
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- d.Run(ctx) }()
+	<-reloaded
+	writeConfig(t, path, config.SourceConfig{Name: "second", Schedule: "10ms"}, config.SourceConfig{Name: "unscheduled"})
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected reloaded source to run")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if seen["unscheduled"] != 0 {
+		t.Fatalf("expected sources without a schedule to be ignored, got %v", seen)
+	}
+}
+
+func TestDaemon_ReloadsConfigWhenIncludedFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	included := filepath.Join(dir, "sources.d", "news.json")
+	if err := os.MkdirAll(filepath.Dir(included), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, path)
+	writeConfig(t, included, config.SourceConfig{Name: "first", Schedule: "10ms"})
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	reloaded := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := &Daemon{
+		ConfigPath: path,
+		Load: func(path string) (*config.Config, error) {
+			cfg, err := loadTestConfig(included)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Files = []string{path, included}
+			return cfg, nil
+		},
+		RunSource: func(_ context.Context, _ *config.Config, source config.SourceConfig) error {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[source.Name]++
+			if source.Name == "first" && seen["first"] == 1 {
+				close(reloaded)
+			}
+			if source.Name == "second" {
+				cancel()
+			}
+			return nil
+		},
+		PollInterval: 5 * time.Millisecond,
+		parse:        testParse,
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- d.Run(ctx) }()
+	<-reloaded
+	writeConfig(t, included, config.SourceConfig{Name: "second", Schedule: "10ms"})
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(included, future, future); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected source from the changed include to run")
+	}
+}
+
+func TestDaemon_CancelsRunsAfterShutdownTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, config.SourceConfig{Name: "stuck", Schedule: "10ms"})
+
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Daemon{
+		ConfigPath: path,
+		Load:       loadTestConfig,
+		RunSource: func(runCtx context.Context, _ *config.Config, _ config.SourceConfig) error {
+			close(started)
+			<-runCtx.Done()
+			cancelled.Store(true)
+			return runCtx.Err()
+		},
+		PollInterval:    time.Hour,
+		ShutdownTimeout: 20 * time.Millisecond,
+		parse:           testParse,
+	}
+	go func() {
+		<-started
+		cancel()
+	}()
+	if err := d.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !cancelled.Load() {
+		t.Fatal("expected running source to be cancelled after the shutdown timeout")
+	}
+}
@@ -1,12 +1,45 @@
 package httpx
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/liuerfire/sieve/internal/retry"
 )
 
 const DefaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36"
 
+// AttemptTimeout bounds each attempt, reading the response body included.
+// It is not a limit on the whole request, so retry delays and Retry-After
+// are not cut short by a slow earlier attempt.
+const AttemptTimeout = 10 * time.Second
+
+var DefaultRetryPolicy = retry.Policy{
+	MaxRetries: 2,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   5 * time.Second,
+}
+
+var (
+	retryPolicy   = DefaultRetryPolicy
+	retryPolicyMu sync.RWMutex
+)
+
+func SetRetryPolicy(policy retry.Policy) {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	retryPolicy = policy
+}
+
+func currentRetryPolicy() retry.Policy {
+	retryPolicyMu.RLock()
+	defer retryPolicyMu.RUnlock()
+	return retryPolicy
+}
+
 type userAgentTransport struct {
 	base http.RoundTripper
 }
@@ -19,12 +52,97 @@ func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	return t.base.RoundTrip(cloned)
 }
 
+type retryTransport struct {
+	base    http.RoundTripper
+	policy  retry.Policy
+	timeout time.Duration
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxRetries := t.policy.MaxRetries
+	if req.Body != nil && req.GetBody == nil {
+		maxRetries = 0
+	}
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if t.timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, t.timeout)
+		}
+		attemptReq := req.Clone(attemptCtx)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		var retryAfter time.Duration
+		switch {
+		case err != nil:
+			cancel()
+			if attempt >= maxRetries || ctx.Err() != nil || !retry.IsRetryable(err) {
+				return nil, err
+			}
+		case retry.RetryableStatus(resp.StatusCode):
+			if attempt >= maxRetries {
+				return withCancel(resp, cancel), nil
+			}
+			retryAfter = retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		default:
+			return withCancel(resp, cancel), nil
+		}
+
+		delay := t.policy.Delay(attempt, retryAfter)
+		if t.policy.OnRetry != nil {
+			reason := err
+			if reason == nil {
+				reason = &retry.StatusError{StatusCode: resp.StatusCode, RetryAfter: retryAfter}
+			}
+			t.policy.OnRetry(attempt+1, delay, reason)
+		}
+		if sleepErr := retry.Sleep(ctx, delay); sleepErr != nil {
+			if resp != nil {
+				return withCancel(resp, cancel), nil
+			}
+			cancel()
+			return nil, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+			cancel()
+		}
+	}
+}
+
+// cancelOnClose releases an attempt's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func withCancel(resp *http.Response, cancel context.CancelFunc) *http.Response {
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp
+}
+
 func NewClient() *http.Client {
 	base := http.DefaultTransport
 	return &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: userAgentTransport{
-			base: base,
+		Transport: retryTransport{
+			base:    userAgentTransport{base: base},
+			policy:  currentRetryPolicy(),
+			timeout: AttemptTimeout,
 		},
 	}
 }
@@ -4,11 +4,14 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/liuerfire/sieve/internal/retry"
 )
 
-func TestHTTPClient_SetsUserAgentAndTimeout(t *testing.T) {
+func TestHTTPClient_SetsUserAgentAndAttemptTimeout(t *testing.T) {
 	var gotUA string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		gotUA = r.Header.Get("User-Agent")
@@ -17,8 +20,11 @@ func TestHTTPClient_SetsUserAgentAndTimeout(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient()
-	if client.Timeout != 10*time.Second {
-		t.Fatalf("expected 10s timeout, got %s", client.Timeout)
+	if client.Timeout != 0 {
+		t.Fatalf("expected no whole-request timeout, got %s", client.Timeout)
+	}
+	if transport := client.Transport.(retryTransport); transport.timeout != AttemptTimeout {
+		t.Fatalf("expected %s attempt timeout, got %s", AttemptTimeout, transport.timeout)
 	}
 
 	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
@@ -36,3 +42,116 @@ func TestHTTPClient_SetsUserAgentAndTimeout(t *testing.T) {
 		t.Fatalf("expected user-agent %q, got %q", DefaultUserAgent, gotUA)
 	}
 }
+
+func TestHTTPClient_RetriesRetryableStatusAndReplaysBody(t *testing.T) {
+	restore := swapRetryPolicy(retry.Policy{MaxRetries: 2, BaseDelay: time.Millisecond})
+	defer restore()
+
+	attempts := 0
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := NewClient().Post(server.URL, "application/json", strings.NewReader(`{"q":1}`))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected retried request to succeed, got %d", resp.StatusCode)
+	}
+	if attempts != 2 || bodies[1] != `{"q":1}` {
+		t.Fatalf("expected body to be replayed, got %d attempts with %#v", attempts, bodies)
+	}
+}
+
+func TestHTTPClient_ReturnsLastResponseWhenRetriesExhausted(t *testing.T) {
+	restore := swapRetryPolicy(retry.Policy{MaxRetries: 1, BaseDelay: time.Millisecond})
+	defer restore()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := NewClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || attempts != 2 {
+		t.Fatalf("expected final 502 after 2 attempts, got %d after %d", resp.StatusCode, attempts)
+	}
+}
+
+func TestHTTPClient_DoesNotRetryClientErrors(t *testing.T) {
+	restore := swapRetryPolicy(retry.Policy{MaxRetries: 2, BaseDelay: time.Millisecond})
+	defer restore()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	resp, err := NewClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func swapRetryPolicy(policy retry.Policy) func() {
+	prev := currentRetryPolicy()
+	SetRetryPolicy(policy)
+	return func() {
+		SetRetryPolicy(prev)
+	}
+}
+
+func TestHTTPClient_TimesOutEachAttemptSeparately(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: retryTransport{
+		base:    http.DefaultTransport,
+		policy:  retry.Policy{MaxRetries: 1, BaseDelay: 100 * time.Millisecond},
+		timeout: 100 * time.Millisecond,
+	}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the retry to get its own timeout, got %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("unexpected body %q (%v)", body, err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+}
@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/liuerfire/sieve/internal/retry"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com/v1"
+	anthropicVersion        = "2023-06-01"
+	anthropicMaxTokens      = 8192
+)
+
+type AnthropicProvider struct {
+	Config Config
+}
+
+func (p AnthropicProvider) Grade(ctx context.Context, req GradeRequest) ([]GradeResult, error) {
+	return gradeWithTool(ctx, p, p.Config, req)
+}
+
+func (p AnthropicProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
+	return summarizeWithTool(ctx, p, p.Config, req)
+}
+
+func (p AnthropicProvider) callTool(ctx context.Context, input toolCallRequest, target any) error {
+	baseURL := strings.TrimRight(p.Config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+
+	name, description, parameters := toolFunction(input.toolSpec)
+	maxTokens := p.Config.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = anthropicMaxTokens
+	}
+	payload := map[string]any{
+		"model":      p.Config.Model,
+		"max_tokens": maxTokens,
+		"messages": []map[string]any{
+			{"role": "user", "content": input.prompt},
+		},
+		"tools": []map[string]any{
+			{
+				"name":         name,
+				"description":  description,
+				"input_schema": parameters,
+			},
+		},
+		"tool_choice": map[string]any{
+			"type": "tool",
+			"name": input.toolName,
+		},
+	}
+	if p.Config.Temperature != nil {
+		payload["temperature"] = *p.Config.Temperature
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", apiKey(p.Config))
+	req.Header.Set("anthropic-version", anthropicVersion)
+	for name, value := range p.Config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("anthropic request failed: %w", retry.NewStatusError(resp))
+	}
+
+	var message struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&message); err != nil {
+		return err
+	}
+	recordUsage(ctx, p.Config, message.Usage.InputTokens, message.Usage.OutputTokens)
+
+	var toolUses []int
+	for i, block := range message.Content {
+		if block.Type == "tool_use" {
+			toolUses = append(toolUses, i)
+		}
+	}
+	if len(toolUses) != 1 {
+		return fmt.Errorf("expected exactly one tool call, got %d (stop_reason %q)", len(toolUses), message.StopReason)
+	}
+	call := message.Content[toolUses[0]]
+	if call.Name != input.toolName {
+		return fmt.Errorf("expected tool call %q, got %q", input.toolName, call.Name)
+	}
+	if len(call.Input) == 0 || string(call.Input) == "null" {
+		return fmt.Errorf("tool call %q returned empty arguments", input.toolName)
+	}
+	return json.Unmarshal(call.Input, target)
+}
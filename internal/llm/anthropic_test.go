@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnthropicProvider_GradeUsesMessagesAPI(t *testing.T) {
+	var requestBody map[string]any
+	var wrote []GradeResult
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Fatalf("expected /messages path, got %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-anthropic-key" {
+			t.Fatalf("unexpected api key header %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got == "" {
+			t.Fatal("expected anthropic-version header")
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if err := json.Unmarshal(data, &requestBody); err != nil {
+			t.Fatalf("Unmarshal request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"content": [
+				{"type": "text", "text": "Grading now."},
+				{
+					"type": "tool_use",
+					"id": "toolu_1",
+					"name": "write_grade_results",
+					"input": {"items": [{"guid": "g1", "level": "critical", "reason": "fit"}]}
+				}
+			],
+			"stop_reason": "tool_use"
+		}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-anthropic-key")
+	provider, err := CreateProvider(Config{
+		Provider: "anthropic",
+		Model:    "claude-test",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+
+	results, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title", Meta: "Meta"}},
+		WriteGradeResults: func(_ context.Context, results []GradeResult) error {
+			wrote = results
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if len(results) != 1 || results[0].GUID != "g1" || results[0].Level != "critical" {
+		t.Fatalf("unexpected grade results: %#v", results)
+	}
+	if len(wrote) != 1 || wrote[0].GUID != "g1" {
+		t.Fatalf("unexpected written grade results: %#v", wrote)
+	}
+	if requestBody["model"] != "claude-test" {
+		t.Fatalf("unexpected model %#v", requestBody["model"])
+	}
+	if _, ok := requestBody["max_tokens"]; !ok {
+		t.Fatal("expected max_tokens in request body")
+	}
+	toolChoice, _ := requestBody["tool_choice"].(map[string]any)
+	if toolChoice["type"] != "tool" || toolChoice["name"] != "write_grade_results" {
+		t.Fatalf("unexpected tool_choice %#v", requestBody["tool_choice"])
+	}
+	tools, _ := requestBody["tools"].([]any)
+	if len(tools) != 1 {
+		t.Fatalf("expected one tool, got %#v", requestBody["tools"])
+	}
+	tool, _ := tools[0].(map[string]any)
+	if tool["name"] != "write_grade_results" {
+		t.Fatalf("unexpected tool %#v", tool)
+	}
+	if _, ok := tool["input_schema"].(map[string]any); !ok {
+		t.Fatalf("expected input_schema in tool, got %#v", tool)
+	}
+}
+
+func TestAnthropicProvider_SummarizeNormalizesGUIDToRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"content": [{
+				"type": "tool_use",
+				"name": "write_summary",
+				"input": {"guid": "translated-guid", "title": "Summary title", "description": "<p>summary</p>", "rejected": false}
+			}]
+		}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-anthropic-key")
+	provider, err := CreateProvider(Config{
+		Provider: "anthropic",
+		Model:    "claude-test",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+
+	result, err := provider.Summarize(context.Background(), SummaryRequest{
+		GUID:  "g1",
+		Title: "Title",
+	})
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if result.GUID != "g1" || result.Title != "Summary title" {
+		t.Fatalf("unexpected summary result: %#v", result)
+	}
+}
+
+func TestAnthropicProvider_ReturnsErrorWithoutToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"content": [{"type": "text", "text": "no tool"}], "stop_reason": "max_tokens"}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-anthropic-key")
+	provider, err := CreateProvider(Config{
+		Provider: "anthropic",
+		Model:    "claude-test",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+
+	_, err = provider.Summarize(context.Background(), SummaryRequest{GUID: "g1"})
+	if err == nil {
+		t.Fatal("expected missing tool_use block to fail")
+	}
+}
+
+func TestAnthropicProvider_ReturnsErrorOnHTTPFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-anthropic-key")
+	provider, err := CreateProvider(Config{
+		Provider: "anthropic",
+		Model:    "claude-test",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+
+	_, err = provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	})
+	if err == nil {
+		t.Fatal("expected HTTP failure to return an error")
+	}
+}
@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+// Cache stores tool-call results on disk, one file per key, so a rerun can
+// reuse responses for prompts it has already paid for.
+type Cache struct {
+	Dir      string
+	TTL      time.Duration
+	ReadOnly bool
+	now      func() time.Time
+}
+
+type cacheEntry struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	Provider  string          `json:"provider"`
+	Model     string          `json:"model"`
+	Tool      string          `json:"tool"`
+	Value     json.RawMessage `json:"value"`
+}
+
+func NewCache(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{Dir: dir, TTL: ttl, now: time.Now}
+}
+
+// cacheKey covers everything that changes the answer: the backend (provider
+// and base URL), the model and its sampling settings, the tool and the
+// prompt. An empty base URL stands for the provider's default.
+func cacheKey(cfg Config, tool, prompt string) string {
+	temperature := "default"
+	if cfg.Temperature != nil {
+		temperature = strconv.FormatFloat(*cfg.Temperature, 'g', -1, 64)
+	}
+	promptHash := sha256.Sum256([]byte(prompt))
+	fields := []string{cfg.Provider, cfg.BaseURL, cfg.Model, temperature, strconv.Itoa(cfg.MaxTokens), tool, hex.EncodeToString(promptHash[:])}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+".json")
+}
+
+func (c *Cache) get(key string, target any) bool {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return false
+	}
+	if c.now().Sub(entry.CreatedAt) > c.TTL {
+		if !c.ReadOnly {
+			_ = os.Remove(c.path(key))
+		}
+		return false
+	}
+	return json.Unmarshal(entry.Value, target) == nil
+}
+
+func (c *Cache) put(key string, entry cacheEntry, value any) error {
+	if c.ReadOnly {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	entry.CreatedAt = c.now().UTC()
+	entry.Value = raw
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CachedProvider answers Grade and Summarize from the cache when the same
+// provider, model and prompt were seen within the TTL. Cache write failures
+// never fail the call.
+type CachedProvider struct {
+	Provider Provider
+	Cache    *Cache
+	Config   Config
+}
+
+func (p CachedProvider) Grade(ctx context.Context, req GradeRequest) ([]GradeResult, error) {
+	key := cacheKey(p.Config, "write_grade_results", buildGradePrompt(req))
+	var cached []GradeResult
+	if p.Cache.get(key, &cached) {
+		if req.WriteGradeResults != nil {
+			if err := req.WriteGradeResults(ctx, cached); err != nil {
+				return nil, err
+			}
+		}
+		return cached, nil
+	}
+	results, err := p.Provider.Grade(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	_ = p.Cache.put(key, p.entry("write_grade_results"), results)
+	return results, nil
+}
+
+func (p CachedProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
+	key := cacheKey(p.Config, "write_summary", buildSummaryPrompt(req))
+	var cached SummaryResult
+	if p.Cache.get(key, &cached) {
+		if req.GUID != "" {
+			cached.GUID = req.GUID
+		}
+		if req.WriteSummary != nil {
+			if err := req.WriteSummary(ctx, cached); err != nil {
+				return SummaryResult{}, err
+			}
+		}
+		return cached, nil
+	}
+	result, err := p.Provider.Summarize(ctx, req)
+	if err != nil {
+		return SummaryResult{}, err
+	}
+	_ = p.Cache.put(key, p.entry("write_summary"), result)
+	return result, nil
+}
+
+func (p CachedProvider) entry(tool string) cacheEntry {
+	return cacheEntry{Provider: p.Config.Provider, Model: p.Config.Model, Tool: tool}
+}
@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	staticProvider
+	grades    int
+	summaries int
+}
+
+func (p *countingProvider) Grade(ctx context.Context, req GradeRequest) ([]GradeResult, error) {
+	p.grades++
+	return p.staticProvider.Grade(ctx, req)
+}
+
+func (p *countingProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
+	p.summaries++
+	return p.staticProvider.Summarize(ctx, req)
+}
+
+func TestCachedProvider_ReusesGradeResults(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	inner := &countingProvider{staticProvider: staticProvider{
+		gradeResults: []GradeResult{{GUID: "g1", Level: "critical", Reason: "fit"}},
+	}}
+	provider := CachedProvider{Provider: inner, Cache: cache, Config: Config{Provider: "openai", Model: "gpt"}}
+	req := GradeRequest{Items: []GradeItem{{GUID: "g1", Title: "Title"}}}
+
+	if _, err := provider.Grade(context.Background(), req); err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	var written []GradeResult
+	req.WriteGradeResults = func(_ context.Context, results []GradeResult) error {
+		written = results
+		return nil
+	}
+	results, err := provider.Grade(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if inner.grades != 1 {
+		t.Fatalf("expected cached second call, got %d provider calls", inner.grades)
+	}
+	if len(results) != 1 || results[0].Level != "critical" || len(written) != 1 {
+		t.Fatalf("unexpected cached results %#v, written %#v", results, written)
+	}
+
+	other := CachedProvider{Provider: inner, Cache: cache, Config: Config{Provider: "openai", Model: "gpt-other"}}
+	if _, err := other.Grade(context.Background(), req); err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if inner.grades != 2 {
+		t.Fatal("expected a different model to miss the cache")
+	}
+
+	temperature := 0.2
+	for _, cfg := range []Config{
+		{Provider: "openai", Model: "gpt", BaseURL: "http://localhost:8000/v1"},
+		{Provider: "openai", Model: "gpt", Temperature: &temperature},
+		{Provider: "openai", Model: "gpt", MaxTokens: 512},
+	} {
+		calls := inner.grades
+		changed := CachedProvider{Provider: inner, Cache: cache, Config: cfg}
+		if _, err := changed.Grade(context.Background(), req); err != nil {
+			t.Fatalf("Grade: %v", err)
+		}
+		if inner.grades != calls+1 {
+			t.Fatalf("expected config %+v to miss the cache", cfg)
+		}
+	}
+}
+
+func TestCachedProvider_ExpiresEntries(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(t.TempDir(), time.Hour)
+	cache.now = func() time.Time { return now }
+	inner := &countingProvider{staticProvider: staticProvider{
+		summaryResult: SummaryResult{GUID: "g1", Title: "T", Description: "D"},
+	}}
+	provider := CachedProvider{Provider: inner, Cache: cache, Config: Config{Provider: "qwen", Model: "qwen-max"}}
+	req := SummaryRequest{GUID: "g1", Title: "Title"}
+
+	for range 2 {
+		result, err := provider.Summarize(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Summarize: %v", err)
+		}
+		if result.GUID != "g1" || result.Title != "T" {
+			t.Fatalf("unexpected summary %#v", result)
+		}
+	}
+	if inner.summaries != 1 {
+		t.Fatalf("expected one provider call within ttl, got %d", inner.summaries)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := provider.Summarize(context.Background(), req); err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if inner.summaries != 2 {
+		t.Fatal("expected expired entry to be refreshed")
+	}
+}
+
+func TestCachedProvider_ReadOnlyDoesNotStore(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	cache.ReadOnly = true
+	inner := &countingProvider{staticProvider: staticProvider{
+		summaryResult: SummaryResult{Title: "T"},
+	}}
+	provider := CachedProvider{Provider: inner, Cache: cache, Config: Config{Provider: "qwen", Model: "qwen-max"}}
+
+	for range 2 {
+		if _, err := provider.Summarize(context.Background(), SummaryRequest{GUID: "g1"}); err != nil {
+			t.Fatalf("Summarize: %v", err)
+		}
+	}
+	if inner.summaries != 2 {
+		t.Fatalf("expected read-only cache to skip writes, got %d provider calls", inner.summaries)
+	}
+}
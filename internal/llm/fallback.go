@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+type Backend struct {
+	Name     string
+	Provider Provider
+}
+
+type FallbackProvider struct {
+	Backends []Backend
+	Logger   *slog.Logger
+}
+
+func BackendName(cfg Config) string {
+	return cfg.Provider + "/" + cfg.Model
+}
+
+func (p FallbackProvider) Grade(ctx context.Context, req GradeRequest) ([]GradeResult, error) {
+	var errs []error
+	for _, backend := range p.Backends {
+		attempt := req
+		var writeErr error
+		if req.WriteGradeResults != nil {
+			attempt.WriteGradeResults = func(ctx context.Context, results []GradeResult) error {
+				writeErr = req.WriteGradeResults(ctx, withGradeBackend(results, backend.Name))
+				return writeErr
+			}
+		}
+		results, err := backend.Provider.Grade(ctx, attempt)
+		if err == nil {
+			results = withGradeBackend(results, backend.Name)
+			p.logInfo("llm grade completed", "backend", backend.Name, "items", len(results))
+			return results, nil
+		}
+		if writeErr != nil || ctx.Err() != nil || errors.Is(err, ErrBudgetExceeded) {
+			return nil, err
+		}
+		p.logWarn("llm grade failed", "backend", backend.Name, "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", backend.Name, err))
+	}
+	return nil, p.joinErrors(errs)
+}
+
+func (p FallbackProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
+	var errs []error
+	for _, backend := range p.Backends {
+		attempt := req
+		var writeErr error
+		if req.WriteSummary != nil {
+			attempt.WriteSummary = func(ctx context.Context, result SummaryResult) error {
+				result.Backend = backend.Name
+				writeErr = req.WriteSummary(ctx, result)
+				return writeErr
+			}
+		}
+		result, err := backend.Provider.Summarize(ctx, attempt)
+		if err == nil {
+			result.Backend = backend.Name
+			p.logInfo("llm summary completed", "backend", backend.Name, "guid", req.GUID)
+			return result, nil
+		}
+		if writeErr != nil || ctx.Err() != nil || errors.Is(err, ErrBudgetExceeded) {
+			return SummaryResult{}, err
+		}
+		p.logWarn("llm summary failed", "backend", backend.Name, "guid", req.GUID, "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", backend.Name, err))
+	}
+	return SummaryResult{}, p.joinErrors(errs)
+}
+
+func (p FallbackProvider) joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("no llm backends configured")
+	}
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return fmt.Errorf("all llm backends failed: %w", errors.Join(errs...))
+}
+
+func (p FallbackProvider) logInfo(msg string, args ...any) {
+	if p.Logger != nil {
+		p.Logger.Info(msg, args...)
+	}
+}
+
+func (p FallbackProvider) logWarn(msg string, args ...any) {
+	if p.Logger != nil {
+		p.Logger.Warn(msg, args...)
+	}
+}
+
+func withGradeBackend(results []GradeResult, backend string) []GradeResult {
+	out := make([]GradeResult, len(results))
+	for i, result := range results {
+		result.Backend = backend
+		out[i] = result
+	}
+	return out
+}
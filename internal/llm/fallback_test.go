@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFallbackProvider_GradeFallsBackAndRecordsBackend(t *testing.T) {
+	provider := FallbackProvider{Backends: []Backend{
+		{Name: "qwen/qwen-plus", Provider: staticProvider{gradeErr: errors.New("quota exceeded")}},
+		{Name: "local/llama3", Provider: staticProvider{
+			gradeResults: []GradeResult{{GUID: "g1", Level: "critical", Reason: "fit"}},
+		}},
+	}}
+
+	results, err := provider.Grade(context.Background(), GradeRequest{})
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if len(results) != 1 || results[0].Backend != "local/llama3" {
+		t.Fatalf("expected result from fallback backend, got %#v", results)
+	}
+}
+
+func TestFallbackProvider_SummarizeReturnsAllErrors(t *testing.T) {
+	provider := FallbackProvider{Backends: []Backend{
+		{Name: "a/one", Provider: staticProvider{summaryErr: errors.New("first failed")}},
+		{Name: "b/two", Provider: staticProvider{summaryErr: errors.New("second failed")}},
+	}}
+
+	_, err := provider.Summarize(context.Background(), SummaryRequest{GUID: "g1"})
+	if err == nil {
+		t.Fatal("expected error when every backend fails")
+	}
+	for _, want := range []string{"a/one: first failed", "b/two: second failed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestFallbackProvider_SingleBackendReturnsOriginalError(t *testing.T) {
+	wantErr := errors.New("boom")
+	provider := FallbackProvider{Backends: []Backend{
+		{Name: "a/one", Provider: staticProvider{gradeErr: wantErr}},
+	}}
+
+	_, err := provider.Grade(context.Background(), GradeRequest{})
+	if err != wantErr {
+		t.Fatalf("expected original error, got %v", err)
+	}
+}
+
+type writingProvider struct {
+	result SummaryResult
+}
+
+func (writingProvider) Grade(context.Context, GradeRequest) ([]GradeResult, error) {
+	return nil, nil
+}
+
+func (p writingProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
+	if err := req.WriteSummary(ctx, p.result); err != nil {
+		return SummaryResult{}, err
+	}
+	return p.result, nil
+}
+
+func TestFallbackProvider_WriterErrorDoesNotFallBack(t *testing.T) {
+	wantErr := errors.New("disk full")
+	calls := 0
+	provider := FallbackProvider{Backends: []Backend{
+		{Name: "a/one", Provider: writingProvider{result: SummaryResult{GUID: "g1"}}},
+		{Name: "b/two", Provider: writingProvider{result: SummaryResult{GUID: "g1"}}},
+	}}
+
+	_, err := provider.Summarize(context.Background(), SummaryRequest{
+		GUID: "g1",
+		WriteSummary: func(_ context.Context, result SummaryResult) error {
+			calls++
+			if result.Backend != "a/one" {
+				t.Fatalf("expected writer to see backend, got %#v", result)
+			}
+			return wantErr
+		},
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected writer error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected writer to be called once, got %d", calls)
+	}
+}
@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/liuerfire/sieve/internal/retry"
+)
+
+const geminiDefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+type geminiFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args"`
+}
+
+type GeminiProvider struct {
+	Config Config
+}
+
+func (p GeminiProvider) Grade(ctx context.Context, req GradeRequest) ([]GradeResult, error) {
+	return gradeWithTool(ctx, p, p.Config, req)
+}
+
+func (p GeminiProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
+	return summarizeWithTool(ctx, p, p.Config, req)
+}
+
+func (p GeminiProvider) callTool(ctx context.Context, input toolCallRequest, target any) error {
+	baseURL := strings.TrimRight(p.Config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = geminiDefaultBaseURL
+	}
+	model := strings.TrimPrefix(p.Config.Model, "models/")
+
+	name, description, parameters := toolFunction(input.toolSpec)
+	payload := map[string]any{
+		"contents": []map[string]any{
+			{
+				"role":  "user",
+				"parts": []map[string]any{{"text": input.prompt}},
+			},
+		},
+		"tools": []map[string]any{
+			{
+				"functionDeclarations": []map[string]any{
+					{
+						"name":        name,
+						"description": description,
+						"parameters":  geminiSchema(parameters),
+					},
+				},
+			},
+		},
+		"toolConfig": map[string]any{
+			"functionCallingConfig": map[string]any{
+				"mode":                 "ANY",
+				"allowedFunctionNames": []string{input.toolName},
+			},
+		},
+	}
+	generationConfig := map[string]any{}
+	if p.Config.Temperature != nil {
+		generationConfig["temperature"] = *p.Config.Temperature
+	}
+	if p.Config.MaxTokens > 0 {
+		generationConfig["maxOutputTokens"] = p.Config.MaxTokens
+	}
+	if len(generationConfig) > 0 {
+		payload["generationConfig"] = generationConfig
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint := baseURL + "/models/" + url.PathEscape(model) + ":generateContent"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", apiKey(p.Config))
+	for name, value := range p.Config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("gemini request failed: %w", retry.NewStatusError(resp))
+	}
+
+	var response struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					FunctionCall *geminiFunctionCall `json:"functionCall"`
+				} `json:"parts"`
+			} `json:"content"`
+			FinishReason string `json:"finishReason"`
+		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return err
+	}
+	recordUsage(ctx, p.Config, response.UsageMetadata.PromptTokenCount, response.UsageMetadata.CandidatesTokenCount)
+	if len(response.Candidates) == 0 {
+		return fmt.Errorf("gemini response contained no candidates")
+	}
+	candidate := response.Candidates[0]
+	var calls []geminiFunctionCall
+	for _, part := range candidate.Content.Parts {
+		if part.FunctionCall != nil {
+			calls = append(calls, *part.FunctionCall)
+		}
+	}
+	if len(calls) != 1 {
+		return fmt.Errorf("expected exactly one tool call, got %d (finishReason %q)", len(calls), candidate.FinishReason)
+	}
+	call := calls[0]
+	if call.Name != input.toolName {
+		return fmt.Errorf("expected tool call %q, got %q", input.toolName, call.Name)
+	}
+	if len(call.Args) == 0 || string(call.Args) == "null" {
+		return fmt.Errorf("tool call %q returned empty arguments", input.toolName)
+	}
+	return json.Unmarshal(call.Args, target)
+}
+
+// geminiSchema strips JSON Schema keywords that Gemini's OpenAPI subset rejects.
+func geminiSchema(schema map[string]any) map[string]any {
+	out := make(map[string]any, len(schema))
+	for key, value := range schema {
+		if key == "additionalProperties" {
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			out[key] = geminiSchema(v)
+		default:
+			out[key] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGeminiProvider_GradeUsesFunctionCalling(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-fast:generateContent" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-gemini-key" {
+			t.Fatalf("unexpected api key header %q", got)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if strings.Contains(string(data), "additionalProperties") {
+			t.Fatalf("expected additionalProperties to be stripped, got %s", data)
+		}
+		if err := json.Unmarshal(data, &requestBody); err != nil {
+			t.Fatalf("Unmarshal request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"candidates": [{
+				"content": {
+					"role": "model",
+					"parts": [{
+						"functionCall": {
+							"name": "write_grade_results",
+							"args": {"items": [{"guid": "g1", "level": "recommended", "reason": "fit"}]}
+						}
+					}]
+				},
+				"finishReason": "STOP"
+			}]
+		}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("GEMINI_API_KEY", "test-gemini-key")
+	provider, err := CreateProvider(Config{
+		Provider: "gemini",
+		Model:    "gemini-fast",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+
+	results, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title", Meta: "Meta"}},
+	})
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if len(results) != 1 || results[0].GUID != "g1" || results[0].Level != "recommended" {
+		t.Fatalf("unexpected grade results: %#v", results)
+	}
+
+	toolConfig, _ := requestBody["toolConfig"].(map[string]any)
+	callingConfig, _ := toolConfig["functionCallingConfig"].(map[string]any)
+	if callingConfig["mode"] != "ANY" {
+		t.Fatalf("unexpected functionCallingConfig %#v", toolConfig)
+	}
+	tools, _ := requestBody["tools"].([]any)
+	if len(tools) != 1 {
+		t.Fatalf("expected one tool, got %#v", requestBody["tools"])
+	}
+	declarations, _ := tools[0].(map[string]any)["functionDeclarations"].([]any)
+	if len(declarations) != 1 || declarations[0].(map[string]any)["name"] != "write_grade_results" {
+		t.Fatalf("unexpected function declarations %#v", declarations)
+	}
+}
+
+func TestGeminiProvider_SummarizeUsesModelForTier(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"candidates": [{
+				"content": {
+					"parts": [{
+						"functionCall": {
+							"name": "write_summary",
+							"args": {"guid": "g1", "title": "Summary title", "description": "<p>summary</p>", "rejected": false}
+						}
+					}]
+				}
+			}]
+		}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("GEMINI_API_KEY", "test-gemini-key")
+	provider, err := CreateProvider(Config{
+		Provider: "gemini",
+		Model:    "models/gemini-powerful",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+
+	result, err := provider.Summarize(context.Background(), SummaryRequest{GUID: "g1", Title: "Title"})
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if result.Title != "Summary title" {
+		t.Fatalf("unexpected summary result: %#v", result)
+	}
+	if gotPath != "/models/gemini-powerful:generateContent" {
+		t.Fatalf("unexpected path %q", gotPath)
+	}
+}
+
+func TestGeminiProvider_ReturnsErrorWithoutFunctionCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"candidates": [{"content": {"parts": [{"text": "no tool"}]}, "finishReason": "MAX_TOKENS"}]}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("GEMINI_API_KEY", "test-gemini-key")
+	provider, err := CreateProvider(Config{
+		Provider: "gemini",
+		Model:    "gemini-fast",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+
+	_, err = provider.Summarize(context.Background(), SummaryRequest{GUID: "g1"})
+	if err == nil {
+		t.Fatal("expected missing functionCall to fail")
+	}
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/liuerfire/sieve/internal/retry"
+)
+
+type openAICompatibleProfile struct {
+	baseURL string
+	headers map[string]string
+	keyless bool
+}
+
+var openAICompatibleProfiles = map[string]openAICompatibleProfile{
+	"openai": {
+		baseURL: "https://api.openai.com/v1",
+	},
+	"qwen": {
+		baseURL: "https://dashscope.aliyuncs.com/compatible-mode/v1",
+	},
+	"openrouter": {
+		baseURL: "https://openrouter.ai/api/v1",
+		headers: map[string]string{
+			"HTTP-Referer": "https://github.com/liuerfire/sieve",
+			"X-Title":      "sieve",
+		},
+	},
+	"grok": {
+		baseURL: "https://api.x.ai/v1",
+	},
+	"local": {
+		baseURL: "http://localhost:11434/v1",
+		keyless: true,
+	},
+}
+
+type OpenAICompatibleProvider struct {
+	Config  Config
+	profile openAICompatibleProfile
+}
+
+func (p OpenAICompatibleProvider) Grade(ctx context.Context, req GradeRequest) ([]GradeResult, error) {
+	return gradeWithTool(ctx, p, p.Config, req)
+}
+
+func (p OpenAICompatibleProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
+	return summarizeWithTool(ctx, p, p.Config, req)
+}
+
+func (p OpenAICompatibleProvider) callTool(ctx context.Context, input toolCallRequest, target any) error {
+	baseURL := strings.TrimRight(p.Config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = strings.TrimRight(p.profile.baseURL, "/")
+	}
+
+	payload := map[string]any{
+		"model": p.Config.Model,
+		"messages": []map[string]any{
+			{"role": "user", "content": input.prompt},
+		},
+		"tools": []map[string]any{
+			input.toolSpec,
+		},
+		"tool_choice": map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name": input.toolName,
+			},
+		},
+	}
+	if p.Config.Temperature != nil {
+		payload["temperature"] = *p.Config.Temperature
+	}
+	if p.Config.MaxTokens > 0 {
+		payload["max_tokens"] = p.Config.MaxTokens
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key := apiKey(p.Config); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	for name, value := range p.profile.headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range p.Config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s request failed: %w", p.Config.Provider, retry.NewStatusError(resp))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return err
+	}
+	recordUsage(ctx, p.Config, completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
+	if len(completion.Choices) == 0 {
+		return fmt.Errorf("%s response contained no choices", p.Config.Provider)
+	}
+	toolCalls := completion.Choices[0].Message.ToolCalls
+	if len(toolCalls) != 1 {
+		return fmt.Errorf("expected exactly one tool call, got %d", len(toolCalls))
+	}
+	call := toolCalls[0]
+	if call.Function.Name != input.toolName {
+		return fmt.Errorf("expected tool call %q, got %q", input.toolName, call.Function.Name)
+	}
+	if call.Function.Arguments == "" {
+		return fmt.Errorf("tool call %q returned empty arguments", input.toolName)
+	}
+	return json.Unmarshal([]byte(call.Function.Arguments), target)
+}
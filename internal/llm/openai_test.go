@@ -0,0 +1,290 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/retry"
+)
+
+const gradeCompletionResponse = `{
+	"choices": [{
+		"message": {
+			"tool_calls": [{
+				"function": {
+					"name": "write_grade_results",
+					"arguments": "{\"items\":[{\"guid\":\"g1\",\"level\":\"optional\",\"reason\":\"fit\"}]}"
+				}
+			}]
+		}
+	}]
+}`
+
+func TestOpenAICompatibleProvider_UsesProviderEnvKey(t *testing.T) {
+	for _, tc := range []struct {
+		provider string
+		envKey   string
+	}{
+		{provider: "openai", envKey: "OPENAI_API_KEY"},
+		{provider: "openrouter", envKey: "OPENROUTER_API_KEY"},
+		{provider: "grok", envKey: "GROK_API_KEY"},
+	} {
+		t.Run(tc.provider, func(t *testing.T) {
+			var gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/chat/completions" {
+					t.Fatalf("expected /chat/completions path, got %s", r.URL.Path)
+				}
+				gotAuth = r.Header.Get("Authorization")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, gradeCompletionResponse)
+			}))
+			defer server.Close()
+
+			t.Setenv(tc.envKey, "key-"+tc.provider)
+			provider, err := CreateProvider(Config{Provider: tc.provider, Model: "test-model", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("CreateProvider: %v", err)
+			}
+			results, err := provider.Grade(context.Background(), GradeRequest{
+				Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+			})
+			if err != nil {
+				t.Fatalf("Grade: %v", err)
+			}
+			if len(results) != 1 || results[0].Level != "optional" {
+				t.Fatalf("unexpected grade results: %#v", results)
+			}
+			if gotAuth != "Bearer key-"+tc.provider {
+				t.Fatalf("unexpected authorization header %q", gotAuth)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleProvider_OpenRouterSendsAttributionHeaders(t *testing.T) {
+	var gotReferer, gotTitle, gotCustom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReferer = r.Header.Get("HTTP-Referer")
+		gotTitle = r.Header.Get("X-Title")
+		gotCustom = r.Header.Get("X-Custom")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, gradeCompletionResponse)
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENROUTER_API_KEY", "test-key")
+	provider, err := CreateProvider(Config{
+		Provider: "openrouter",
+		Model:    "test-model",
+		BaseURL:  server.URL,
+		Headers:  map[string]string{"X-Title": "my-feeds", "X-Custom": "1"},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	if _, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	}); err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if gotReferer == "" {
+		t.Fatal("expected default HTTP-Referer header")
+	}
+	if gotTitle != "my-feeds" {
+		t.Fatalf("expected configured X-Title to override default, got %q", gotTitle)
+	}
+	if gotCustom != "1" {
+		t.Fatalf("expected configured header, got %q", gotCustom)
+	}
+}
+
+func TestOpenAICompatibleProvider_LocalIsKeyless(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, gradeCompletionResponse)
+	}))
+	defer server.Close()
+
+	_ = os.Unsetenv("OPENAI_API_KEY")
+	provider, err := CreateProvider(Config{Provider: "local", Model: "llama3", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	if _, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	}); err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if gotAuth != "" {
+		t.Fatalf("expected no authorization header, got %q", gotAuth)
+	}
+}
+
+func TestOpenAICompatibleProvider_ErrorNamesProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"error":"quota"}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("GROK_API_KEY", "test-key")
+	provider, err := CreateProvider(Config{Provider: "grok", Model: "grok-test", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	_, err = provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "grok request failed") {
+		t.Fatalf("expected grok request error, got %v", err)
+	}
+}
+
+func TestOpenAICompatibleProvider_SendsTierSettings(t *testing.T) {
+	var requestBody map[string]any
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, gradeCompletionResponse)
+	}))
+	defer server.Close()
+
+	temperature := 0.3
+	t.Setenv("CUSTOM_OPENAI_KEY", "custom-key")
+	provider, err := CreateProvider(Config{
+		Provider:    "openai",
+		Model:       "test-model",
+		BaseURL:     server.URL,
+		APIKeyEnv:   "CUSTOM_OPENAI_KEY",
+		Temperature: &temperature,
+		MaxTokens:   1024,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	if _, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	}); err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if gotAuth != "Bearer custom-key" {
+		t.Fatalf("unexpected authorization header %q", gotAuth)
+	}
+	if requestBody["temperature"] != 0.3 || requestBody["max_tokens"] != float64(1024) {
+		t.Fatalf("unexpected sampling settings: %#v", requestBody)
+	}
+}
+
+func TestOpenAICompatibleProvider_RetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, gradeCompletionResponse)
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	provider, err := CreateProvider(Config{
+		Provider: "openai",
+		Model:    "test-model",
+		BaseURL:  server.URL,
+		Retry:    retry.Policy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	results, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	})
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if len(results) != 1 || attempts != 2 {
+		t.Fatalf("expected success on second attempt, got %d attempts and %#v", attempts, results)
+	}
+}
+
+func TestOpenAICompatibleProvider_TimesOutStalledAttempts(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			<-release
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, gradeCompletionResponse)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	provider, err := CreateProvider(Config{
+		Provider: "openai",
+		Model:    "test-model",
+		BaseURL:  server.URL,
+		Retry:    retry.Policy{MaxRetries: 1, BaseDelay: time.Millisecond},
+		Timeout:  50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	results, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	})
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if len(results) != 1 || attempts.Load() != 2 {
+		t.Fatalf("expected the stalled attempt to time out and be retried, got %d attempts", attempts.Load())
+	}
+}
+
+func TestOpenAICompatibleProvider_DoesNotRetryBadRequest(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	provider, err := CreateProvider(Config{
+		Provider: "openai",
+		Model:    "test-model",
+		BaseURL:  server.URL,
+		Retry:    retry.Policy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	if _, err := provider.Grade(context.Background(), GradeRequest{
+		Items: []GradeItem{{GUID: "g1", Title: "Title"}},
+	}); err == nil {
+		t.Fatal("expected bad request to fail")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
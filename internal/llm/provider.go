@@ -1,20 +1,45 @@
 package llm
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/retry"
 )
 
 type Config struct {
-	Provider string
-	Model    string
-	BaseURL  string
+	Provider    string
+	Model       string
+	BaseURL     string
+	APIKeyEnv   string
+	Headers     map[string]string
+	Temperature *float64
+	MaxTokens   int
+	Retry       retry.Policy
+	// Timeout bounds each attempt of a call, so a stalled response is
+	// retried instead of hanging the run. Zero means DefaultAttemptTimeout.
+	Timeout time.Duration
+}
+
+// DefaultAttemptTimeout is generous because a long grading batch can take
+// minutes to generate.
+const DefaultAttemptTimeout = 3 * time.Minute
+
+func (c Config) attemptTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultAttemptTimeout
+}
+
+var DefaultRetryPolicy = retry.Policy{
+	MaxRetries: 2,
+	BaseDelay:  2 * time.Second,
+	MaxDelay:   30 * time.Second,
 }
 
 type GradeItem struct {
@@ -39,9 +64,10 @@ type GradeRequest struct {
 }
 
 type GradeResult struct {
-	GUID   string
-	Level  string
-	Reason string
+	GUID    string
+	Level   string
+	Reason  string
+	Backend string `json:",omitempty"`
 }
 
 type SummaryRequest struct {
@@ -60,6 +86,7 @@ type SummaryResult struct {
 	Title       string
 	Description string
 	Rejected    bool
+	Backend     string `json:",omitempty"`
 }
 
 type Provider interface {
@@ -67,20 +94,28 @@ type Provider interface {
 	Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error)
 }
 
-type QwenProvider struct {
-	Config Config
-}
-
 func CreateProvider(cfg Config) (Provider, error) {
 	switch cfg.Provider {
-	case "qwen":
-		if os.Getenv(providerEnvKeys[cfg.Provider]) == "" {
-			return nil, fmt.Errorf("%s not set", providerEnvKeys[cfg.Provider])
+	case "anthropic":
+		if err := requireAPIKey(cfg); err != nil {
+			return nil, err
+		}
+		return AnthropicProvider{Config: cfg}, nil
+	case "gemini":
+		if err := requireAPIKey(cfg); err != nil {
+			return nil, err
+		}
+		return GeminiProvider{Config: cfg}, nil
+	}
+	if profile, ok := openAICompatibleProfiles[cfg.Provider]; ok {
+		if !profile.keyless || cfg.APIKeyEnv != "" {
+			if err := requireAPIKey(cfg); err != nil {
+				return nil, err
+			}
 		}
-		return QwenProvider{Config: cfg}, nil
-	default:
-		return nil, fmt.Errorf("unsupported provider %q", cfg.Provider)
+		return OpenAICompatibleProvider{Config: cfg, profile: profile}, nil
 	}
+	return nil, fmt.Errorf("unsupported provider %q", cfg.Provider)
 }
 
 var providerEnvKeys = map[string]string{
@@ -92,33 +127,88 @@ var providerEnvKeys = map[string]string{
 	"grok":       "GROK_API_KEY",
 }
 
-func (p QwenProvider) Grade(ctx context.Context, req GradeRequest) ([]GradeResult, error) {
+func apiKeyEnv(cfg Config) string {
+	if cfg.APIKeyEnv != "" {
+		return cfg.APIKeyEnv
+	}
+	return providerEnvKeys[cfg.Provider]
+}
+
+func apiKey(cfg Config) string {
+	if env := apiKeyEnv(cfg); env != "" {
+		return os.Getenv(env)
+	}
+	return ""
+}
+
+func requireAPIKey(cfg Config) error {
+	if apiKey(cfg) == "" {
+		return fmt.Errorf("%s not set", apiKeyEnv(cfg))
+	}
+	return nil
+}
+
+type toolCallRequest struct {
+	prompt   string
+	toolName string
+	toolSpec map[string]any
+}
+
+type toolCaller interface {
+	callTool(ctx context.Context, input toolCallRequest, target any) error
+}
+
+func gradeWithTool(ctx context.Context, caller toolCaller, cfg Config, req GradeRequest) ([]GradeResult, error) {
 	type responseEnvelope struct {
 		Items []GradeResult `json:"items"`
 	}
-	var envelope responseEnvelope
-	if err := p.callTool(ctx, chatCompletionRequest{
-		prompt:   buildGradePrompt(req),
-		toolName: "write_grade_results",
-		toolSpec: gradeResultsToolDefinition(),
-	}, &envelope); err != nil {
+	prompt := buildGradePrompt(req)
+	items, err := retry.Do(ctx, cfg.Retry, func(ctx context.Context) ([]GradeResult, error) {
+		if err := checkBudget(ctx); err != nil {
+			return nil, retry.Permanent(err)
+		}
+		ctx, cancel := context.WithTimeout(ctx, cfg.attemptTimeout())
+		defer cancel()
+		var envelope responseEnvelope
+		if err := caller.callTool(ctx, toolCallRequest{
+			prompt:   prompt,
+			toolName: "write_grade_results",
+			toolSpec: gradeResultsToolDefinition(),
+		}, &envelope); err != nil {
+			return nil, err
+		}
+		return envelope.Items, nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	if req.WriteGradeResults != nil {
-		if err := req.WriteGradeResults(ctx, envelope.Items); err != nil {
+		if err := req.WriteGradeResults(ctx, items); err != nil {
 			return nil, err
 		}
 	}
-	return envelope.Items, nil
+	return items, nil
 }
 
-func (p QwenProvider) Summarize(ctx context.Context, req SummaryRequest) (SummaryResult, error) {
-	var result SummaryResult
-	if err := p.callTool(ctx, chatCompletionRequest{
-		prompt:   buildSummaryPrompt(req),
-		toolName: "write_summary",
-		toolSpec: summaryToolDefinition(),
-	}, &result); err != nil {
+func summarizeWithTool(ctx context.Context, caller toolCaller, cfg Config, req SummaryRequest) (SummaryResult, error) {
+	prompt := buildSummaryPrompt(req)
+	result, err := retry.Do(ctx, cfg.Retry, func(ctx context.Context) (SummaryResult, error) {
+		if err := checkBudget(ctx); err != nil {
+			return SummaryResult{}, retry.Permanent(err)
+		}
+		ctx, cancel := context.WithTimeout(ctx, cfg.attemptTimeout())
+		defer cancel()
+		var result SummaryResult
+		if err := caller.callTool(ctx, toolCallRequest{
+			prompt:   prompt,
+			toolName: "write_summary",
+			toolSpec: summaryToolDefinition(),
+		}, &result); err != nil {
+			return SummaryResult{}, err
+		}
+		return result, nil
+	})
+	if err != nil {
 		return SummaryResult{}, err
 	}
 	if req.GUID != "" {
@@ -132,85 +222,12 @@ func (p QwenProvider) Summarize(ctx context.Context, req SummaryRequest) (Summar
 	return result, nil
 }
 
-type chatCompletionRequest struct {
-	prompt   string
-	toolName string
-	toolSpec map[string]any
-}
-
-func (p QwenProvider) callTool(ctx context.Context, input chatCompletionRequest, target any) error {
-	baseURL := strings.TrimRight(p.Config.BaseURL, "/")
-	if baseURL == "" {
-		baseURL = "https://dashscope.aliyuncs.com/compatible-mode/v1"
-	}
-
-	payload := map[string]any{
-		"model": p.Config.Model,
-		"messages": []map[string]any{
-			{"role": "user", "content": input.prompt},
-		},
-		"tools": []map[string]any{
-			input.toolSpec,
-		},
-		"tool_choice": map[string]any{
-			"type": "function",
-			"function": map[string]any{
-				"name": input.toolName,
-			},
-		},
-	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+os.Getenv(providerEnvKeys["qwen"]))
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		data, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("qwen request failed: %s", strings.TrimSpace(string(data)))
-	}
-
-	var completion struct {
-		Choices []struct {
-			Message struct {
-				ToolCalls []struct {
-					Function struct {
-						Name      string `json:"name"`
-						Arguments string `json:"arguments"`
-					} `json:"function"`
-				} `json:"tool_calls"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
-		return err
-	}
-	if len(completion.Choices) == 0 {
-		return fmt.Errorf("qwen response contained no choices")
-	}
-	toolCalls := completion.Choices[0].Message.ToolCalls
-	if len(toolCalls) != 1 {
-		return fmt.Errorf("expected exactly one tool call, got %d", len(toolCalls))
-	}
-	call := toolCalls[0]
-	if call.Function.Name != input.toolName {
-		return fmt.Errorf("expected tool call %q, got %q", input.toolName, call.Function.Name)
-	}
-	if call.Function.Arguments == "" {
-		return fmt.Errorf("tool call %q returned empty arguments", input.toolName)
-	}
-	return json.Unmarshal([]byte(call.Function.Arguments), target)
+func toolFunction(spec map[string]any) (name string, description string, parameters map[string]any) {
+	function, _ := spec["function"].(map[string]any)
+	name, _ = function["name"].(string)
+	description, _ = function["description"].(string)
+	parameters, _ = function["parameters"].(map[string]any)
+	return name, description, parameters
 }
 
 func gradeResultsToolDefinition() map[string]any {
@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+)
+
+var ErrBudgetExceeded = errors.New("llm budget exceeded")
+
+type Usage struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	Cost             float64 `json:"cost,omitempty"`
+}
+
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+func (u *Usage) add(other Usage) {
+	u.Calls += other.Calls
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.Cost += other.Cost
+}
+
+// Price is the cost in USD per million prompt (input) and completion (output)
+// tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+type Budget struct {
+	MaxTokens int
+	MaxCost   float64
+}
+
+type UsageRecorder interface {
+	RecordUsage(backend string, model string, usage Usage)
+	BudgetExceeded() bool
+}
+
+type usageRecorderKey struct{}
+
+func WithUsageRecorder(ctx context.Context, recorder UsageRecorder) context.Context {
+	return context.WithValue(ctx, usageRecorderKey{}, recorder)
+}
+
+func recordUsage(ctx context.Context, cfg Config, promptTokens, completionTokens int) {
+	if recorder, ok := ctx.Value(usageRecorderKey{}).(UsageRecorder); ok {
+		recorder.RecordUsage(BackendName(cfg), cfg.Model, Usage{
+			Calls:            1,
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+		})
+	}
+}
+
+func checkBudget(ctx context.Context) error {
+	if recorder, ok := ctx.Value(usageRecorderKey{}).(UsageRecorder); ok && recorder.BudgetExceeded() {
+		return ErrBudgetExceeded
+	}
+	return nil
+}
+
+// UsageTracker totals token usage for one run, per plugin and per backend,
+// and prices it when the model has a configured price.
+type UsageTracker struct {
+	pricing map[string]Price
+	budget  Budget
+
+	mu      sync.Mutex
+	total   Usage
+	plugins map[string]map[string]Usage
+}
+
+type UsageReport struct {
+	Total   Usage                  `json:"total"`
+	Plugins map[string]PluginUsage `json:"plugins,omitempty"`
+}
+
+type PluginUsage struct {
+	Total    Usage            `json:"total"`
+	Backends map[string]Usage `json:"backends"`
+}
+
+func NewUsageTracker(pricing map[string]Price, budget Budget) *UsageTracker {
+	return &UsageTracker{
+		pricing: pricing,
+		budget:  budget,
+		plugins: map[string]map[string]Usage{},
+	}
+}
+
+func (t *UsageTracker) Plugin(name string) UsageRecorder {
+	return pluginRecorder{tracker: t, plugin: name}
+}
+
+func (t *UsageTracker) record(plugin, backend, model string, usage Usage) {
+	price, ok := t.pricing[backend]
+	if !ok {
+		price = t.pricing[model]
+	}
+	usage.Cost = (float64(usage.PromptTokens)*price.Input + float64(usage.CompletionTokens)*price.Output) / 1e6
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total.add(usage)
+	backends := t.plugins[plugin]
+	if backends == nil {
+		backends = map[string]Usage{}
+		t.plugins[plugin] = backends
+	}
+	current := backends[backend]
+	current.add(usage)
+	backends[backend] = current
+}
+
+func (t *UsageTracker) BudgetExceeded() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.budget.MaxTokens > 0 && t.total.TotalTokens() >= t.budget.MaxTokens {
+		return true
+	}
+	return t.budget.MaxCost > 0 && t.total.Cost >= t.budget.MaxCost
+}
+
+func (t *UsageTracker) Report() UsageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	report := UsageReport{Total: t.total, Plugins: make(map[string]PluginUsage, len(t.plugins))}
+	for plugin, backends := range t.plugins {
+		usage := PluginUsage{Backends: maps.Clone(backends)}
+		for _, name := range slices.Sorted(maps.Keys(backends)) {
+			usage.Total.add(backends[name])
+		}
+		report.Plugins[plugin] = usage
+	}
+	return report
+}
+
+type pluginRecorder struct {
+	tracker *UsageTracker
+	plugin  string
+}
+
+func (r pluginRecorder) RecordUsage(backend string, model string, usage Usage) {
+	r.tracker.record(r.plugin, backend, model, usage)
+}
+
+func (r pluginRecorder) BudgetExceeded() bool {
+	return r.tracker.BudgetExceeded()
+}
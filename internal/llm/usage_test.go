@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUsageTracker_RecordsUsageAndStopsAtBudget(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, strings.Replace(gradeCompletionResponse, `"choices"`, `"usage": {"prompt_tokens": 1000, "completion_tokens": 200}, "choices"`, 1))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	provider, err := CreateProvider(Config{Provider: "openai", Model: "gpt-test", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("CreateProvider: %v", err)
+	}
+	tracker := NewUsageTracker(map[string]Price{"gpt-test": {Input: 1, Output: 10}}, Budget{MaxTokens: 1000})
+	ctx := WithUsageRecorder(context.Background(), tracker.Plugin("builtin/llm-grade"))
+	req := GradeRequest{Items: []GradeItem{{GUID: "g1", Title: "Title"}}}
+
+	if _, err := provider.Grade(ctx, req); err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if _, err := provider.Grade(ctx, req); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected budget to stop the second request, got %d requests", calls)
+	}
+
+	report := tracker.Report()
+	usage := report.Plugins["builtin/llm-grade"].Backends["openai/gpt-test"]
+	if usage.Calls != 1 || usage.PromptTokens != 1000 || usage.CompletionTokens != 200 {
+		t.Fatalf("unexpected usage: %#v", report)
+	}
+	if report.Total.Cost != 0.003 {
+		t.Fatalf("expected priced cost 0.003, got %v", report.Total.Cost)
+	}
+}
+
+func TestFallbackProvider_DoesNotFallBackWhenBudgetExceeded(t *testing.T) {
+	fallback := &countingProvider{}
+	provider := FallbackProvider{Backends: []Backend{
+		{Name: "primary", Provider: staticProvider{gradeErr: ErrBudgetExceeded}},
+		{Name: "fallback", Provider: fallback},
+	}}
+	if _, err := provider.Grade(context.Background(), GradeRequest{}); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if fallback.grades != 0 {
+		t.Fatal("expected fallback backend to be skipped")
+	}
+}
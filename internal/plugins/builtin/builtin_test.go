@@ -14,9 +14,25 @@ import (
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/plugins"
+	"github.com/liuerfire/sieve/internal/storage"
 	"github.com/liuerfire/sieve/internal/types"
 )
 
+// TestMain runs the tests from a scratch directory because the LLM plugins
+// and the dedup history write under ./output.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "builtin-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func testRunContext(source string) plugins.Context {
 	return plugins.Context{
 		SourceName: source,
@@ -122,6 +138,13 @@ func TestDeduplicate_MarksProcessedItemsRejected(t *testing.T) {
 	if got[0].Level == types.LevelRejected || got[1].Level == types.LevelRejected {
 		t.Fatal("expected first pass items to remain non-rejected")
 	}
+	if _, err := os.Stat(storage.ProcessedPath("source")); !os.IsNotExist(err) {
+		t.Fatalf("expected history to wait for the run to commit, got err=%v", err)
+	}
+
+	if err := (DeduplicatePlugin{}).Commit(context.Background(), got, config.PluginEntry{Name: "builtin/deduplicate"}, testRunContext("source")); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
 
 	got, err = DeduplicatePlugin{}.ProcessItems(context.Background(), items, config.PluginEntry{Name: "builtin/deduplicate"}, testRunContext("source"))
 	if err != nil {
@@ -132,6 +155,44 @@ func TestDeduplicate_MarksProcessedItemsRejected(t *testing.T) {
 	}
 }
 
+func TestDeduplicate_CommitsCandidatesDroppedLater(t *testing.T) {
+	dir := t.TempDir()
+	prevWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	defer func() { _ = os.Chdir(prevWD) }()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+
+	items := []types.FeedItem{
+		types.FeedItem{GUID: "a", Title: "A"}.WithDefaults(),
+		types.FeedItem{GUID: "b", Title: "B"}.WithDefaults(),
+	}
+	entry := config.PluginEntry{Name: "builtin/deduplicate"}
+
+	got, err := DeduplicatePlugin{}.ProcessItems(context.Background(), items, entry, testRunContext("source"))
+	if err != nil {
+		t.Fatalf("ProcessItems returned error: %v", err)
+	}
+	// A later plugin drops "b", so only "a" is reported.
+	if err := (DeduplicatePlugin{}).Commit(context.Background(), got[:1], entry, testRunContext("source")); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if _, err := os.Stat(storage.PendingPath("source")); !os.IsNotExist(err) {
+		t.Fatalf("expected pending guids to be cleared by Commit, got err=%v", err)
+	}
+
+	got, err = DeduplicatePlugin{}.ProcessItems(context.Background(), items, entry, testRunContext("source"))
+	if err != nil {
+		t.Fatalf("ProcessItems returned error: %v", err)
+	}
+	if got[0].Level != types.LevelRejected || got[1].Level != types.LevelRejected {
+		t.Fatalf("expected the dropped candidate to be recorded too, got %#v", got)
+	}
+}
+
 func TestDeduplicate_DryRunReturnsOriginalItems(t *testing.T) {
 	dir := t.TempDir()
 	if err := os.MkdirAll(filepath.Join(dir, "output"), 0o755); err != nil {
@@ -32,4 +32,8 @@ func cleanZeroWidth(value string) string {
 
 func init() {
 	plugins.Register("builtin/clean-text", CleanTextPlugin{})
+	plugins.Describe("builtin/clean-text", plugins.Spec{
+		Phases:  []string{config.PhaseProcess},
+		Options: struct{}{},
+	})
 }
@@ -18,7 +18,7 @@ type CollectRSSPlugin struct {
 }
 
 type collectRSSOptions struct {
-	URL      string `json:"url"`
+	URL      string `json:"url" option:"required"`
 	MaxItems int    `json:"maxItems"`
 }
 
@@ -66,5 +66,9 @@ func (CollectRSSPlugin) Collect(ctx context.Context, entry config.PluginEntry, r
 
 func init() {
 	plugins.Register("builtin/collect-rss", CollectRSSPlugin{})
+	plugins.Describe("builtin/collect-rss", plugins.Spec{
+		Phases:  []string{config.PhaseCollect},
+		Options: collectRSSOptions{},
+	})
 	_ = httpx.DefaultUserAgent
 }
@@ -17,7 +17,7 @@ type CollectRSSHubPlugin struct {
 }
 
 type collectRSSHubOptions struct {
-	Route    string `json:"route"`
+	Route    string `json:"route" option:"required"`
 	MaxItems int    `json:"maxItems"`
 }
 
@@ -85,4 +85,8 @@ func (CollectRSSHubPlugin) Collect(ctx context.Context, entry config.PluginEntry
 
 func init() {
 	plugins.Register("builtin/collect-rsshub", CollectRSSHubPlugin{})
+	plugins.Describe("builtin/collect-rsshub", plugins.Spec{
+		Phases:  []string{config.PhaseCollect},
+		Options: collectRSSHubOptions{},
+	})
 }
@@ -2,7 +2,7 @@ package builtin
 
 import (
 	"context"
-	"path/filepath"
+	"os"
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/plugins"
@@ -19,28 +19,27 @@ func (DeduplicatePlugin) ProcessItems(_ context.Context, items []types.FeedItem,
 		return items, nil
 	}
 
-	tracker, err := storage.NewGUIDTracker(filepath.Join("output", runCtx.SourceName+"-processed.json"))
+	tracker, err := storage.NewGUIDTracker(storage.ProcessedPath(runCtx.SourceName))
 	if err != nil {
 		return nil, err
 	}
 
+	// Only the items that are still new are candidates. They are saved as
+	// pending and recorded in the history by Commit once the run has
+	// reported, including ones a later plugin drops, so those are not
+	// fetched and processed again on every run.
 	newGuids := make(map[string]struct{}, len(items))
+	var pending []string
 	for _, item := range items {
 		if item.GUID == "" {
 			continue
 		}
-		if !tracker.IsProcessed(item.GUID) {
+		if _, ok := newGuids[item.GUID]; !ok && !tracker.IsProcessed(item.GUID) {
 			newGuids[item.GUID] = struct{}{}
+			pending = append(pending, item.GUID)
 		}
 	}
-
-	processed := make([]string, 0, len(newGuids))
-	for guid := range newGuids {
-		processed = append(processed, guid)
-	}
-	tracker.MarkProcessed(processed)
-	tracker.Cleanup()
-	if err := tracker.Persist(); err != nil {
+	if err := storage.SavePending(storage.PendingPath(runCtx.SourceName), pending); err != nil {
 		return nil, err
 	}
 
@@ -60,6 +59,38 @@ func (DeduplicatePlugin) ProcessItems(_ context.Context, items []types.FeedItem,
 	return result, nil
 }
 
+// Commit records this run's candidates, and any reported item, in the
+// history so later runs reject them.
+func (DeduplicatePlugin) Commit(_ context.Context, items []types.FeedItem, _ config.PluginEntry, runCtx plugins.Context) error {
+	tracker, err := storage.NewGUIDTracker(storage.ProcessedPath(runCtx.SourceName))
+	if err != nil {
+		return err
+	}
+	pendingPath := storage.PendingPath(runCtx.SourceName)
+	guids, err := storage.LoadPending(pendingPath)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.GUID != "" {
+			guids = append(guids, item.GUID)
+		}
+	}
+	tracker.MarkProcessed(guids)
+	tracker.Cleanup()
+	if err := tracker.Persist(); err != nil {
+		return err
+	}
+	if err := os.Remove(pendingPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func init() {
 	plugins.Register("builtin/deduplicate", DeduplicatePlugin{})
+	plugins.Describe("builtin/deduplicate", plugins.Spec{
+		Phases:  []string{config.PhaseProcess},
+		Options: struct{}{},
+	})
 }
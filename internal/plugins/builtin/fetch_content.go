@@ -124,4 +124,8 @@ func (FetchContentPlugin) ProcessItems(ctx context.Context, items []types.FeedIt
 
 func init() {
 	plugins.Register("builtin/fetch-content", FetchContentPlugin{})
+	plugins.Describe("builtin/fetch-content", plugins.Spec{
+		Phases:  []string{config.PhaseProcess},
+		Options: struct{}{},
+	})
 }
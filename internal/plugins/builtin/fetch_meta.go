@@ -55,4 +55,8 @@ func (FetchMetaPlugin) ProcessItems(ctx context.Context, items []types.FeedItem,
 
 func init() {
 	plugins.Register("builtin/fetch-meta", FetchMetaPlugin{})
+	plugins.Describe("builtin/fetch-meta", plugins.Spec{
+		Phases:  []string{config.PhaseProcess},
+		Options: struct{}{},
+	})
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/llm"
@@ -17,17 +19,25 @@ type LLMGradePlugin struct {
 }
 
 type llmGradeOptions struct {
-	GlobalHighInterest string `json:"globalHighInterest"`
-	GlobalInterest     string `json:"globalInterest"`
-	GlobalUninterested string `json:"globalUninterested"`
-	GlobalAvoid        string `json:"globalAvoid"`
-	HighInterest       string `json:"highInterest"`
-	Interest           string `json:"interest"`
-	Uninterested       string `json:"uninterested"`
-	Avoid              string `json:"avoid"`
-	Context            string `json:"context"`
+	GlobalHighInterest config.StringList `json:"globalHighInterest"`
+	GlobalInterest     config.StringList `json:"globalInterest"`
+	GlobalUninterested config.StringList `json:"globalUninterested"`
+	GlobalAvoid        config.StringList `json:"globalAvoid"`
+	HighInterest       config.StringList `json:"highInterest"`
+	Interest           config.StringList `json:"interest"`
+	Uninterested       config.StringList `json:"uninterested"`
+	Avoid              config.StringList `json:"avoid"`
+	Context            string            `json:"context"`
+	BatchSize          int               `json:"batchSize"`
+	MaxConcurrency     int               `json:"maxConcurrency"`
+	MissingRetries     *int              `json:"missingRetries"`
 }
 
+const (
+	defaultGradeConcurrency    = 4
+	defaultGradeMissingRetries = 2
+)
+
 func (LLMGradePlugin) ProcessItems(ctx context.Context, items []types.FeedItem, entry config.PluginEntry, runCtx plugins.Context) ([]types.FeedItem, error) {
 	adapter, err := requireProvider(runCtx, "balanced")
 	if err != nil {
@@ -39,14 +49,22 @@ func (LLMGradePlugin) ProcessItems(ctx context.Context, items []types.FeedItem,
 			return nil, err
 		}
 	}
+	if opts.BatchSize < 0 {
+		return nil, fmt.Errorf("llm-grade: batchSize must not be negative")
+	}
+	if opts.MaxConcurrency <= 0 {
+		opts.MaxConcurrency = defaultGradeConcurrency
+	}
+	missingRetries := defaultGradeMissingRetries
+	if opts.MissingRetries != nil {
+		missingRetries = max(*opts.MissingRetries, 0)
+	}
 
-	toGrade := make([]types.FeedItem, 0)
 	reqItems := make([]llm.GradeItem, 0)
 	for _, item := range items {
 		if item.Level == types.LevelRejected {
 			continue
 		}
-		toGrade = append(toGrade, item)
 		reqItems = append(reqItems, llm.GradeItem{
 			GUID:  item.GUID,
 			Title: item.Title,
@@ -57,56 +75,187 @@ func (LLMGradePlugin) ProcessItems(ctx context.Context, items []types.FeedItem,
 		return items, nil
 	}
 
-	results, err := adapter.Grade(ctx, llm.GradeRequest{
-		SourceContext:      runCtx.SourceContext,
-		Context:            opts.Context,
-		GlobalHigh:         opts.GlobalHighInterest,
-		GlobalInterest:     opts.GlobalInterest,
-		GlobalUninterested: opts.GlobalUninterested,
-		GlobalAvoid:        opts.GlobalAvoid,
-		High:               opts.HighInterest,
-		Interest:           opts.Interest,
-		Uninterested:       opts.Uninterested,
-		Avoid:              opts.Avoid,
-		Items:              reqItems,
-		WriteGradeResults: func(ctx context.Context, results []llm.GradeResult) error {
-			if runCtx.IsDryRun {
-				return nil
-			}
-			return writeGradeResultsFile(ctx, filepath.Join("output", runCtx.SourceName+"-llm-grade.json"), results)
+	grader := &chunkGrader{
+		adapter: adapter,
+		runCtx:  runCtx,
+		base: llm.GradeRequest{
+			SourceContext:      runCtx.SourceContext,
+			Context:            opts.Context,
+			GlobalHigh:         opts.GlobalHighInterest.String(),
+			GlobalInterest:     opts.GlobalInterest.String(),
+			GlobalUninterested: opts.GlobalUninterested.String(),
+			GlobalAvoid:        opts.GlobalAvoid.String(),
+			High:               opts.HighInterest.String(),
+			Interest:           opts.Interest.String(),
+			Uninterested:       opts.Uninterested.String(),
+			Avoid:              opts.Avoid.String(),
 		},
-	})
-	if err != nil {
+		missingRetries: missingRetries,
+		path:           filepath.Join("output", runCtx.SourceName+"-llm-grade.json"),
+		order:          reqItems,
+		results:        make(map[string]llm.GradeResult, len(reqItems)),
+	}
+	if err := grader.gradeAll(ctx, reqItems, opts.BatchSize, opts.MaxConcurrency); err != nil {
 		return nil, err
 	}
-	if len(results) != len(reqItems) {
-		return nil, fmt.Errorf("grade result count mismatch")
+
+	out := make([]types.FeedItem, 0, len(items))
+	for _, item := range items {
+		if result, ok := grader.results[item.GUID]; ok {
+			item.Level = types.FeedLevel(result.Level)
+			item.Reason = result.Reason
+			item.GradeBackend = result.Backend
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// chunkGrader grades items in batches and re-requests only the GUIDs a
+// response dropped, so one truncated tool call doesn't fail the whole run.
+type chunkGrader struct {
+	adapter        llm.Provider
+	runCtx         plugins.Context
+	base           llm.GradeRequest
+	missingRetries int
+	path           string
+	order          []llm.GradeItem
+
+	mu      sync.Mutex
+	results map[string]llm.GradeResult
+}
+
+func (g *chunkGrader) gradeAll(ctx context.Context, items []llm.GradeItem, batchSize, concurrency int) error {
+	if batchSize == 0 {
+		batchSize = len(items)
 	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var firstErr error
+	var firstErrOnce sync.Once
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+dispatch:
+	for chunk := range slices.Chunk(items, batchSize) {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := g.gradeChunk(ctx, chunk); err != nil {
+				firstErrOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
 
-	gradeMap := make(map[string]llm.GradeResult, len(results))
+func (g *chunkGrader) gradeChunk(ctx context.Context, chunk []llm.GradeItem) error {
+	pending := chunk
+	var invalidLevel string
+	for attempt := 0; ; attempt++ {
+		req := g.base
+		req.Items = pending
+		req.WriteGradeResults = func(ctx context.Context, results []llm.GradeResult) error {
+			g.accept(pending, results)
+			if g.runCtx.IsDryRun {
+				return nil
+			}
+			return g.write(ctx)
+		}
+		results, err := g.adapter.Grade(ctx, req)
+		if err != nil {
+			return err
+		}
+		if level := g.accept(pending, results); level != "" {
+			invalidLevel = level
+		}
+		pending = g.missing(pending)
+		if len(pending) == 0 {
+			return nil
+		}
+		if attempt >= g.missingRetries {
+			if invalidLevel != "" {
+				return fmt.Errorf("grade results missing for %d of %d items (invalid grade level %q)", len(pending), len(chunk), invalidLevel)
+			}
+			return fmt.Errorf("grade results missing for %d of %d items", len(pending), len(chunk))
+		}
+		if g.runCtx.Logger != nil {
+			g.runCtx.Logger.Warn("regrading items missing from response", "source", g.runCtx.SourceName, "missing", len(pending), "batch", len(chunk), "invalidLevel", invalidLevel)
+		}
+	}
+}
+
+// accept records valid results for requested GUIDs. Unknown GUIDs, duplicates
+// and results without a reason or with an invalid level are ignored, so the
+// item counts as missing and is picked up by the next attempt. It returns the
+// last invalid level it saw, if any.
+func (g *chunkGrader) accept(requested []llm.GradeItem, results []llm.GradeResult) (invalidLevel string) {
+	wanted := make(map[string]struct{}, len(requested))
+	for _, item := range requested {
+		wanted[item.GUID] = struct{}{}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	for _, result := range results {
-		if result.GUID == "" || result.Reason == "" {
-			return nil, fmt.Errorf("invalid grade result")
+		if _, ok := wanted[result.GUID]; !ok || result.Reason == "" {
+			continue
+		}
+		if _, ok := g.results[result.GUID]; ok {
+			continue
 		}
 		switch types.FeedLevel(result.Level) {
 		case types.LevelCritical, types.LevelRecommended, types.LevelOptional, types.LevelRejected:
 		default:
-			return nil, fmt.Errorf("invalid grade level %q", result.Level)
+			invalidLevel = result.Level
+			continue
 		}
-		gradeMap[result.GUID] = result
+		g.results[result.GUID] = result
 	}
+	return invalidLevel
+}
 
-	out := make([]types.FeedItem, 0, len(items))
-	for _, item := range items {
-		if result, ok := gradeMap[item.GUID]; ok {
-			item.Level = types.FeedLevel(result.Level)
-			item.Reason = result.Reason
+func (g *chunkGrader) missing(requested []llm.GradeItem) []llm.GradeItem {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var missing []llm.GradeItem
+	for _, item := range requested {
+		if _, ok := g.results[item.GUID]; !ok {
+			missing = append(missing, item)
 		}
-		out = append(out, item)
 	}
-	return out, nil
+	return missing
+}
+
+func (g *chunkGrader) write(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	results := make([]llm.GradeResult, 0, len(g.results))
+	for _, item := range g.order {
+		if result, ok := g.results[item.GUID]; ok {
+			results = append(results, result)
+		}
+	}
+	return writeGradeResultsFile(ctx, g.path, results)
 }
 
 func init() {
 	plugins.Register("builtin/llm-grade", LLMGradePlugin{})
+	plugins.Describe("builtin/llm-grade", plugins.Spec{
+		Phases:   []string{config.PhaseProcess},
+		Options:  llmGradeOptions{},
+		LLMTiers: []string{"balanced"},
+	})
 }
@@ -3,11 +3,18 @@ package builtin
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/llm"
@@ -131,15 +138,53 @@ func TestLLMGrade_RejectsUnknownLevel(t *testing.T) {
 	_, err := LLMGradePlugin{}.ProcessItems(context.Background(), items, config.PluginEntry{
 		Name: "builtin/llm-grade",
 	}, plugins.Context{
-		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
 		LLM: func(string) (llm.Provider, error) {
 			return &staticProvider{
 				gradeResults: []llm.GradeResult{{GUID: "g1", Level: "recommend", Reason: "fit"}},
 			}, nil
 		},
 	})
-	if err == nil {
-		t.Fatal("expected invalid LLM level to return an error")
+	if err == nil || !strings.Contains(err.Error(), `invalid grade level "recommend"`) {
+		t.Fatalf("expected invalid LLM level to fail once retries run out, got %v", err)
+	}
+}
+
+func TestLLMGrade_RegradesInvalidLevels(t *testing.T) {
+	var requests [][]string
+	provider := funcProvider{grade: func(_ context.Context, req llm.GradeRequest) ([]llm.GradeResult, error) {
+		var guids []string
+		for _, item := range req.Items {
+			guids = append(guids, item.GUID)
+		}
+		requests = append(requests, guids)
+		if len(requests) == 1 {
+			return []llm.GradeResult{
+				{GUID: "g0", Level: "critical", Reason: "fit"},
+				{GUID: "g1", Level: "recommend", Reason: "fit"},
+			}, nil
+		}
+		return []llm.GradeResult{{GUID: "g1", Level: "recommended", Reason: "retry"}}, nil
+	}}
+
+	got, err := LLMGradePlugin{}.ProcessItems(context.Background(), summarizeTestItems(2), config.PluginEntry{
+		Name: "builtin/llm-grade",
+	}, plugins.Context{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
+		LLM: func(string) (llm.Provider, error) {
+			return provider, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessItems: %v", err)
+	}
+	if len(requests) != 2 || !slices.Equal(requests[1], []string{"g1"}) {
+		t.Fatalf("expected only the invalid item to be regraded, got %v", requests)
+	}
+	if got[0].Level != types.LevelCritical || got[1].Level != types.LevelRecommended {
+		t.Fatalf("unexpected graded items: %#v", got)
 	}
 }
 
@@ -344,3 +389,377 @@ func TestLLMSummarize_WritesAggregateOutputForRun(t *testing.T) {
 		t.Fatalf("unexpected summary aggregate output: %#v", payload)
 	}
 }
+
+func TestLLMGrade_RecordsBackendOnItem(t *testing.T) {
+	items := []types.FeedItem{
+		types.FeedItem{Title: "A", GUID: "g1"}.WithDefaults(),
+	}
+
+	got, err := LLMGradePlugin{}.ProcessItems(context.Background(), items, config.PluginEntry{
+		Name: "builtin/llm-grade",
+	}, plugins.Context{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
+		LLM: func(string) (llm.Provider, error) {
+			return &staticProvider{
+				gradeResults: []llm.GradeResult{{GUID: "g1", Level: "critical", Reason: "fit", Backend: "local/llama3"}},
+			}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessItems: %v", err)
+	}
+	if got[0].GradeBackend != "local/llama3" || got[0].Extra["gradeBackend"] != nil {
+		t.Fatalf("expected grade backend on the item and not in extra, got %#v", got[0])
+	}
+
+	var extra map[string]any
+	got, err = LLMSummarizePlugin{}.ProcessItems(context.Background(), got, config.PluginEntry{
+		Name: "builtin/llm-summarize",
+	}, plugins.Context{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
+		LLM: func(string) (llm.Provider, error) {
+			return funcProvider{summarize: func(_ context.Context, req llm.SummaryRequest) (llm.SummaryResult, error) {
+				extra = req.Extra
+				return llm.SummaryResult{GUID: req.GUID, Backend: "openai/gpt"}, nil
+			}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessItems: %v", err)
+	}
+	if len(extra) != 0 || got[0].SummaryBackend != "openai/gpt" {
+		t.Fatalf("expected backends to stay out of the summary prompt, got extra %#v and item %#v", extra, got[0])
+	}
+}
+
+type funcProvider struct {
+	grade     func(context.Context, llm.GradeRequest) ([]llm.GradeResult, error)
+	summarize func(context.Context, llm.SummaryRequest) (llm.SummaryResult, error)
+}
+
+func (p funcProvider) Grade(ctx context.Context, req llm.GradeRequest) ([]llm.GradeResult, error) {
+	results, err := p.grade(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.WriteGradeResults != nil {
+		if err := req.WriteGradeResults(ctx, results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (p funcProvider) Summarize(ctx context.Context, req llm.SummaryRequest) (llm.SummaryResult, error) {
+	result, err := p.summarize(ctx, req)
+	if err != nil {
+		return llm.SummaryResult{}, err
+	}
+	if req.WriteSummary != nil {
+		if err := req.WriteSummary(ctx, result); err != nil {
+			return llm.SummaryResult{}, err
+		}
+	}
+	return result, nil
+}
+
+func summarizeTestItems(n int) []types.FeedItem {
+	items := make([]types.FeedItem, 0, n)
+	for i := range n {
+		items = append(items, types.FeedItem{
+			Title: fmt.Sprintf("Old %d", i),
+			GUID:  fmt.Sprintf("g%d", i),
+		}.WithDefaults())
+	}
+	return items
+}
+
+func TestLLMSummarize_RunsConcurrentlyWithinLimitAndPreservesOrder(t *testing.T) {
+	dir := withWorkingDir(t)
+	var active, peak atomic.Int32
+	provider := funcProvider{summarize: func(_ context.Context, req llm.SummaryRequest) (llm.SummaryResult, error) {
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			prev := peak.Load()
+			if current <= prev || peak.CompareAndSwap(prev, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return llm.SummaryResult{GUID: req.GUID, Title: "New " + req.GUID}, nil
+	}}
+
+	items := summarizeTestItems(8)
+	got, err := LLMSummarizePlugin{}.ProcessItems(context.Background(), items, config.PluginEntry{
+		Name:    "builtin/llm-summarize",
+		Options: mustJSON(map[string]any{"maxConcurrency": 3}),
+	}, plugins.Context{
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		SourceName: "source",
+		LLM: func(string) (llm.Provider, error) {
+			return provider, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessItems: %v", err)
+	}
+	if peak.Load() < 2 || peak.Load() > 3 {
+		t.Fatalf("expected between 2 and 3 concurrent calls, got %d", peak.Load())
+	}
+	for i, item := range got {
+		if item.Title != fmt.Sprintf("New g%d", i) {
+			t.Fatalf("expected output order to be preserved, got %#v", got)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "output", "source-llm-summary.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var payload struct {
+		Items []llm.SummaryResult `json:"items"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(payload.Items) != 8 || payload.Items[0].GUID != "g0" || payload.Items[7].GUID != "g7" {
+		t.Fatalf("unexpected summary output: %#v", payload)
+	}
+}
+
+func TestLLMSummarize_FailFastCancelsRemainingWork(t *testing.T) {
+	var calls atomic.Int32
+	provider := funcProvider{summarize: func(ctx context.Context, req llm.SummaryRequest) (llm.SummaryResult, error) {
+		calls.Add(1)
+		if req.GUID == "g0" {
+			return llm.SummaryResult{}, errors.New("quota exceeded")
+		}
+		select {
+		case <-ctx.Done():
+			return llm.SummaryResult{}, ctx.Err()
+		case <-time.After(time.Second):
+			return llm.SummaryResult{GUID: req.GUID}, nil
+		}
+	}}
+
+	start := time.Now()
+	_, err := LLMSummarizePlugin{}.ProcessItems(context.Background(), summarizeTestItems(10), config.PluginEntry{
+		Name:    "builtin/llm-summarize",
+		Options: mustJSON(map[string]any{"maxConcurrency": 2}),
+	}, plugins.Context{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
+		LLM: func(string) (llm.Provider, error) {
+			return provider, nil
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("expected first error, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("expected remaining work to be cancelled")
+	}
+	if calls.Load() > 3 {
+		t.Fatalf("expected dispatch to stop after the failure, got %d calls", calls.Load())
+	}
+}
+
+func TestLLMSummarize_ErrorPolicies(t *testing.T) {
+	provider := funcProvider{summarize: func(_ context.Context, req llm.SummaryRequest) (llm.SummaryResult, error) {
+		if req.GUID == "g1" || req.GUID == "g3" {
+			return llm.SummaryResult{}, errors.New("failed " + req.GUID)
+		}
+		return llm.SummaryResult{GUID: req.GUID, Title: "New " + req.GUID}, nil
+	}}
+	runCtx := plugins.Context{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
+		LLM: func(string) (llm.Provider, error) {
+			return provider, nil
+		},
+	}
+
+	_, err := LLMSummarizePlugin{}.ProcessItems(context.Background(), summarizeTestItems(4), config.PluginEntry{
+		Name:    "builtin/llm-summarize",
+		Options: mustJSON(map[string]any{"maxConcurrency": 4, "errorPolicy": "collect"}),
+	}, runCtx)
+	if err == nil || !strings.Contains(err.Error(), "failed g1") || !strings.Contains(err.Error(), "failed g3") {
+		t.Fatalf("expected collected errors, got %v", err)
+	}
+
+	got, err := LLMSummarizePlugin{}.ProcessItems(context.Background(), summarizeTestItems(4), config.PluginEntry{
+		Name:    "builtin/llm-summarize",
+		Options: mustJSON(map[string]any{"maxConcurrency": 4, "errorPolicy": "skip"}),
+	}, runCtx)
+	if err != nil {
+		t.Fatalf("expected skip policy to succeed, got %v", err)
+	}
+	if got[0].Title != "New g0" || got[1].Title != "Old 1" || got[2].Title != "New g2" || got[3].Title != "Old 3" {
+		t.Fatalf("unexpected items under skip policy: %#v", got)
+	}
+
+	_, err = LLMSummarizePlugin{}.ProcessItems(context.Background(), summarizeTestItems(1), config.PluginEntry{
+		Name:    "builtin/llm-summarize",
+		Options: mustJSON(map[string]any{"errorPolicy": "ignore"}),
+	}, runCtx)
+	if err == nil {
+		t.Fatal("expected unsupported error policy to fail")
+	}
+}
+
+func TestLLMSummarize_StopsOverBudgetWhateverThePolicy(t *testing.T) {
+	for _, policy := range []string{"skip", "collect"} {
+		var calls atomic.Int32
+		provider := funcProvider{summarize: func(_ context.Context, req llm.SummaryRequest) (llm.SummaryResult, error) {
+			if calls.Add(1) > 1 {
+				return llm.SummaryResult{}, llm.ErrBudgetExceeded
+			}
+			return llm.SummaryResult{GUID: req.GUID, Title: "New " + req.GUID}, nil
+		}}
+
+		got, err := LLMSummarizePlugin{}.ProcessItems(context.Background(), summarizeTestItems(4), config.PluginEntry{
+			Name:    "builtin/llm-summarize",
+			Options: mustJSON(map[string]any{"maxConcurrency": 1, "errorPolicy": policy}),
+		}, plugins.Context{
+			Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+			IsDryRun: true,
+			LLM: func(string) (llm.Provider, error) {
+				return provider, nil
+			},
+		})
+		if !errors.Is(err, llm.ErrBudgetExceeded) || got != nil {
+			t.Fatalf("%s: expected the budget error, got %v with items %#v", policy, err, got)
+		}
+		if strings.Contains(err.Error(), "\n") {
+			t.Fatalf("%s: expected the budget error on its own, got %q", policy, err)
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("%s: expected dispatch to stop at the budget, got %d calls", policy, calls.Load())
+		}
+	}
+}
+
+func TestLLMGrade_GradesInBatches(t *testing.T) {
+	dir := withWorkingDir(t)
+	var mu sync.Mutex
+	var batches []int
+	provider := funcProvider{grade: func(_ context.Context, req llm.GradeRequest) ([]llm.GradeResult, error) {
+		mu.Lock()
+		batches = append(batches, len(req.Items))
+		mu.Unlock()
+		results := make([]llm.GradeResult, 0, len(req.Items))
+		for _, item := range req.Items {
+			results = append(results, llm.GradeResult{GUID: item.GUID, Level: "optional", Reason: "fit"})
+		}
+		return results, nil
+	}}
+
+	items := summarizeTestItems(7)
+	got, err := LLMGradePlugin{}.ProcessItems(context.Background(), items, config.PluginEntry{
+		Name:    "builtin/llm-grade",
+		Options: mustJSON(map[string]any{"batchSize": 3}),
+	}, plugins.Context{
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		SourceName: "source",
+		LLM: func(string) (llm.Provider, error) {
+			return provider, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessItems: %v", err)
+	}
+	slices.Sort(batches)
+	if !slices.Equal(batches, []int{1, 3, 3}) {
+		t.Fatalf("unexpected batch sizes: %v", batches)
+	}
+	for _, item := range got {
+		if item.Level != types.LevelOptional {
+			t.Fatalf("expected every item to be graded, got %#v", item)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "output", "source-llm-grade.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var payload struct {
+		Items []llm.GradeResult `json:"items"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(payload.Items) != 7 || payload.Items[0].GUID != "g0" || payload.Items[6].GUID != "g6" {
+		t.Fatalf("unexpected grade output: %#v", payload)
+	}
+}
+
+func TestLLMGrade_RegradesOnlyMissingItems(t *testing.T) {
+	var requests [][]string
+	provider := funcProvider{grade: func(_ context.Context, req llm.GradeRequest) ([]llm.GradeResult, error) {
+		var guids []string
+		for _, item := range req.Items {
+			guids = append(guids, item.GUID)
+		}
+		requests = append(requests, guids)
+		if len(requests) == 1 {
+			// Truncated response with a duplicate and an unknown GUID.
+			return []llm.GradeResult{
+				{GUID: "g0", Level: "critical", Reason: "fit"},
+				{GUID: "g0", Level: "rejected", Reason: "dup"},
+				{GUID: "other", Level: "optional", Reason: "fit"},
+			}, nil
+		}
+		results := make([]llm.GradeResult, 0, len(req.Items))
+		for _, item := range req.Items {
+			results = append(results, llm.GradeResult{GUID: item.GUID, Level: "optional", Reason: "retry"})
+		}
+		return results, nil
+	}}
+
+	got, err := LLMGradePlugin{}.ProcessItems(context.Background(), summarizeTestItems(3), config.PluginEntry{
+		Name: "builtin/llm-grade",
+	}, plugins.Context{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
+		LLM: func(string) (llm.Provider, error) {
+			return provider, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessItems: %v", err)
+	}
+	if len(requests) != 2 || !slices.Equal(requests[1], []string{"g1", "g2"}) {
+		t.Fatalf("expected only missing items to be regraded, got %v", requests)
+	}
+	if got[0].Level != types.LevelCritical || got[1].Reason != "retry" || got[2].Reason != "retry" {
+		t.Fatalf("unexpected graded items: %#v", got)
+	}
+}
+
+func TestLLMGrade_FailsWhenItemsStayMissing(t *testing.T) {
+	calls := 0
+	provider := funcProvider{grade: func(context.Context, llm.GradeRequest) ([]llm.GradeResult, error) {
+		calls++
+		return nil, nil
+	}}
+
+	_, err := LLMGradePlugin{}.ProcessItems(context.Background(), summarizeTestItems(2), config.PluginEntry{
+		Name:    "builtin/llm-grade",
+		Options: mustJSON(map[string]any{"missingRetries": 1}),
+	}, plugins.Context{
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IsDryRun: true,
+		LLM: func(string) (llm.Provider, error) {
+			return provider, nil
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "grade results missing for 2 of 2 items") {
+		t.Fatalf("expected missing results error, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected one regrade attempt, got %d calls", calls)
+	}
+}
@@ -3,8 +3,11 @@ package builtin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/llm"
@@ -20,8 +23,15 @@ type llmSummarizeOptions struct {
 	PreferredLanguage string `json:"preferredLanguage"`
 	Context           string `json:"context"`
 	MaxConcurrency    int    `json:"maxConcurrency"`
+	ErrorPolicy       string `json:"errorPolicy"`
 }
 
+const (
+	summarizeErrorFailFast = "failFast"
+	summarizeErrorCollect  = "collect"
+	summarizeErrorSkip     = "skip"
+)
+
 func (LLMSummarizePlugin) ProcessItems(ctx context.Context, items []types.FeedItem, entry config.PluginEntry, runCtx plugins.Context) ([]types.FeedItem, error) {
 	adapter, err := requireProvider(runCtx, "powerful")
 	if err != nil {
@@ -36,61 +46,141 @@ func (LLMSummarizePlugin) ProcessItems(ctx context.Context, items []types.FeedIt
 	if opts.PreferredLanguage == "" {
 		opts.PreferredLanguage = "zh-CN"
 	}
+	if opts.MaxConcurrency <= 0 {
+		opts.MaxConcurrency = 1
+	}
+	switch opts.ErrorPolicy {
+	case "":
+		opts.ErrorPolicy = summarizeErrorFailFast
+	case summarizeErrorFailFast, summarizeErrorCollect, summarizeErrorSkip:
+	default:
+		return nil, fmt.Errorf("llm-summarize: unsupported errorPolicy %q", opts.ErrorPolicy)
+	}
 
 	summaryPath := filepath.Join("output", runCtx.SourceName+"-llm-summary.json")
-	writtenSummaries := make([]llm.SummaryResult, 0, len(items))
-	out := make([]types.FeedItem, 0, len(items))
-	for _, item := range items {
+	var writeMu sync.Mutex
+	written := make([]*llm.SummaryResult, len(items))
+	writeSummary := func(ctx context.Context, index int, result llm.SummaryResult) error {
+		if runCtx.IsDryRun {
+			return nil
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		written[index] = &result
+		summaries := make([]llm.SummaryResult, 0, len(written))
+		for _, summary := range written {
+			if summary != nil {
+				summaries = append(summaries, *summary)
+			}
+		}
+		return writeSummaryResultsFile(ctx, summaryPath, summaries)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := slices.Clone(items)
+	errs := make([]error, len(items))
+	var firstErr error
+	var firstErrOnce sync.Once
+	sem := make(chan struct{}, opts.MaxConcurrency)
+	var wg sync.WaitGroup
+dispatch:
+	for i, item := range items {
 		if item.Level == types.LevelRejected {
-			out = append(out, item)
 			continue
 		}
 		if skip, _ := item.Extra["skipSummarize"].(bool); skip {
-			out = append(out, item)
 			continue
 		}
-
-		result, err := adapter.Summarize(ctx, llm.SummaryRequest{
-			PreferredLanguage: opts.PreferredLanguage,
-			SourceContext:     runCtx.SourceContext,
-			Context:           opts.Context,
-			GUID:              item.GUID,
-			Title:             item.Title,
-			Description:       item.Description,
-			Extra:             item.Extra,
-			WriteSummary: func(ctx context.Context, result llm.SummaryResult) error {
-				if runCtx.IsDryRun {
-					return nil
-				}
-				writtenSummaries = append(writtenSummaries, result)
-				return writeSummaryResultsFile(ctx, summaryPath, writtenSummaries)
-			},
-		})
-		if err != nil {
-			return nil, err
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
 		}
-		if result.GUID == "" {
-			result.GUID = item.GUID
-		}
-		if result.GUID != item.GUID {
-			return nil, fmt.Errorf("summary guid mismatch")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			summarized, err := summarizeItem(ctx, adapter, item, opts, runCtx, func(ctx context.Context, result llm.SummaryResult) error {
+				return writeSummary(ctx, i, result)
+			})
+			if err != nil {
+				errs[i] = fmt.Errorf("summarize %q: %w", item.GUID, err)
+				// An exhausted budget stops the run whatever the policy,
+				// so no item goes out unsummarized.
+				if opts.ErrorPolicy == summarizeErrorFailFast || errors.Is(err, llm.ErrBudgetExceeded) {
+					firstErrOnce.Do(func() {
+						firstErr = errs[i]
+						cancel()
+					})
+				}
+				return
+			}
+			out[i] = summarized
+		}()
+	}
+	wg.Wait()
+
+	if errors.Is(firstErr, llm.ErrBudgetExceeded) {
+		return nil, firstErr
+	}
+	switch opts.ErrorPolicy {
+	case summarizeErrorFailFast:
+		if firstErr != nil {
+			return nil, firstErr
 		}
-		if result.Rejected {
-			item.Level = types.LevelRejected
-			out = append(out, item)
-			continue
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-		if result.Title != "" {
-			item.Title = result.Title
+	case summarizeErrorCollect:
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
 		}
-		if result.Description != "" {
-			item.Description = result.Description
+	case summarizeErrorSkip:
+		for _, err := range errs {
+			if err != nil && runCtx.Logger != nil {
+				runCtx.Logger.Warn("summarize item failed", "source", runCtx.SourceName, "error", err)
+			}
 		}
-		out = append(out, item)
 	}
 	return out, nil
 }
 
+func summarizeItem(ctx context.Context, adapter llm.Provider, item types.FeedItem, opts llmSummarizeOptions, runCtx plugins.Context, write func(context.Context, llm.SummaryResult) error) (types.FeedItem, error) {
+	result, err := adapter.Summarize(ctx, llm.SummaryRequest{
+		PreferredLanguage: opts.PreferredLanguage,
+		SourceContext:     runCtx.SourceContext,
+		Context:           opts.Context,
+		GUID:              item.GUID,
+		Title:             item.Title,
+		Description:       item.Description,
+		Extra:             item.Extra,
+		WriteSummary:      write,
+	})
+	if err != nil {
+		return item, err
+	}
+	if result.GUID == "" {
+		result.GUID = item.GUID
+	}
+	if result.GUID != item.GUID {
+		return item, fmt.Errorf("summary guid mismatch")
+	}
+	item.SummaryBackend = result.Backend
+	if result.Rejected {
+		item.Level = types.LevelRejected
+		return item, nil
+	}
+	if result.Title != "" {
+		item.Title = result.Title
+	}
+	if result.Description != "" {
+		item.Description = result.Description
+	}
+	return item, nil
+}
+
 func requireProvider(runCtx plugins.Context, tier string) (llm.Provider, error) {
 	if runCtx.LLM == nil {
 		return nil, fmt.Errorf("llm provider not configured")
@@ -107,4 +197,9 @@ func stringFromExtra(value any) string {
 
 func init() {
 	plugins.Register("builtin/llm-summarize", LLMSummarizePlugin{})
+	plugins.Describe("builtin/llm-summarize", plugins.Spec{
+		Phases:   []string{config.PhaseProcess},
+		Options:  llmSummarizeOptions{},
+		LLMTiers: []string{"powerful"},
+	})
 }
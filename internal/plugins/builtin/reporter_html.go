@@ -19,7 +19,7 @@ type ReporterHTMLPlugin struct {
 }
 
 type reporterHTMLOptions struct {
-	OutputPath string `json:"outputPath"`
+	OutputPath string `json:"outputPath" option:"required,path"`
 	SourceName string `json:"sourceName,omitempty"`
 	Title      string `json:"title,omitempty"`
 }
@@ -347,4 +347,8 @@ func badgeLabel(level types.FeedLevel) string {
 
 func init() {
 	plugins.Register("builtin/reporter-html", ReporterHTMLPlugin{})
+	plugins.Describe("builtin/reporter-html", plugins.Spec{
+		Phases:  []string{config.PhaseReport},
+		Options: reporterHTMLOptions{},
+	})
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/plugins"
@@ -18,7 +19,7 @@ type ReporterRSSPlugin struct {
 }
 
 type reporterRSSOptions struct {
-	OutputPath string `json:"outputPath"`
+	OutputPath string `json:"outputPath" option:"required,path"`
 	SourceName string `json:"sourceName,omitempty"`
 	Title      string `json:"title,omitempty"`
 	ShowReason *bool  `json:"showReason,omitempty"`
@@ -114,7 +115,7 @@ func (ReporterRSSPlugin) Report(_ context.Context, items []types.FeedItem, entry
 		Channel: rssChannel{
 			Title:         opts.Title,
 			Description:   fmt.Sprintf("Filtered content for %s", opts.SourceName),
-			LastBuildDate: "",
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
 			Items:         allItems,
 		},
 	}); err != nil {
@@ -155,4 +156,8 @@ func writeRSS(path string, feed rssFeed) error {
 
 func init() {
 	plugins.Register("builtin/reporter-rss", ReporterRSSPlugin{})
+	plugins.Describe("builtin/reporter-rss", plugins.Spec{
+		Phases:  []string{config.PhaseReport},
+		Options: reporterRSSOptions{},
+	})
 }
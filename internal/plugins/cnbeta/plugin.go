@@ -64,4 +64,8 @@ func (Plugin) ProcessItems(ctx context.Context, items []types.FeedItem, _ config
 
 func init() {
 	plugins.Register("cnbeta", Plugin{})
+	plugins.Describe("cnbeta", plugins.Spec{
+		Phases:  []string{config.PhaseProcess},
+		Options: struct{}{},
+	})
 }
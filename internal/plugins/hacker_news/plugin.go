@@ -110,4 +110,8 @@ func extractComments(children []hnChild, depth int, maxDepth int) []map[string]a
 
 func init() {
 	plugins.Register("hacker-news", Plugin{})
+	plugins.Describe("hacker-news", plugins.Spec{
+		Phases:  []string{config.PhaseProcess},
+		Options: struct{}{},
+	})
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 
 	"github.com/liuerfire/sieve/internal/config"
@@ -30,6 +32,15 @@ type Plugin interface {
 	Report(ctx context.Context, items []types.FeedItem, entry config.PluginEntry, runCtx Context) error
 }
 
+// Committer is implemented by plugins that keep state across runs. The
+// workflow calls Commit with the reported items once every reporter has
+// succeeded, so state only advances for published runs. It is never called
+// for a failed run, a run where a reporter failed and was skipped by its
+// onError policy, or a dry run.
+type Committer interface {
+	Commit(ctx context.Context, items []types.FeedItem, entry config.PluginEntry, runCtx Context) error
+}
+
 type BasePlugin struct{}
 
 func (BasePlugin) Collect(context.Context, config.PluginEntry, Context) (CollectResult, error) {
@@ -61,6 +72,56 @@ func Register(name string, plugin Plugin) {
 	registry[name] = plugin
 }
 
+// Spec describes what a registered plugin does and needs, for config
+// validation and `sieve plugins`.
+type Spec struct {
+	// Phases lists the phases the plugin does work in.
+	Phases []string
+	// Options is a value of the struct the plugin decodes its options into.
+	// Config validation rejects unknown keys and mistyped values against it.
+	Options any
+	// Env lists environment variables the plugin reads.
+	Env []string
+	// LLMTiers lists the model tiers the plugin asks runCtx.LLM for.
+	LLMTiers []string
+}
+
+type Registration struct {
+	Name string
+	Spec Spec
+}
+
+var specs = map[string]Spec{}
+
+// Describe records spec for the plugin registered under name.
+func Describe(name string, spec Spec) {
+	if spec.Options != nil {
+		config.RegisterOptions(name, spec.Options)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	specs[name] = spec
+}
+
+// Registered lists every registered plugin by name with its spec, if any.
+func Registered() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := slices.Sorted(maps.Keys(registry))
+	registrations := make([]Registration, 0, len(names))
+	for _, name := range names {
+		registrations = append(registrations, Registration{Name: name, Spec: specs[name]})
+	}
+	return registrations
+}
+
+func Lookup(name string) (Spec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	spec, ok := specs[name]
+	return spec, ok
+}
+
 func Load(entries []config.PluginEntry) ([]LoadedPlugin, error) {
 	registryMu.RLock()
 	defer registryMu.RUnlock()
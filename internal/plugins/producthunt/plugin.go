@@ -34,7 +34,11 @@ func (Plugin) Collect(ctx context.Context, entry config.PluginEntry, _ plugins.C
 		return plugins.CollectResult{}, fmt.Errorf("PRODUCTHUNT_API_KEY not set")
 	}
 	var opts collectOptions
-	_ = json.Unmarshal(entry.Options, &opts)
+	if len(entry.Options) > 0 {
+		if err := json.Unmarshal(entry.Options, &opts); err != nil {
+			return plugins.CollectResult{}, err
+		}
+	}
 	if opts.Limit == 0 {
 		opts.Limit = 10
 	}
@@ -164,4 +168,9 @@ func swapNow(fn func() time.Time) func() {
 
 func init() {
 	plugins.Register("producthunt", Plugin{})
+	plugins.Describe("producthunt", plugins.Spec{
+		Phases:  []string{config.PhaseCollect},
+		Options: collectOptions{},
+		Env:     []string{"PRODUCTHUNT_API_KEY"},
+	})
 }
@@ -63,4 +63,8 @@ func trimLeadingEmoji(s string) string {
 
 func init() {
 	plugins.Register("zaihuapd", Plugin{})
+	plugins.Describe("zaihuapd", plugins.Spec{
+		Phases:  []string{config.PhaseProcess},
+		Options: struct{}{},
+	})
 }
@@ -27,7 +27,11 @@ type collectOptions struct {
 
 func (Plugin) Collect(ctx context.Context, entry config.PluginEntry, _ plugins.Context) (plugins.CollectResult, error) {
 	var opts collectOptions
-	_ = json.Unmarshal(entry.Options, &opts)
+	if len(entry.Options) > 0 {
+		if err := json.Unmarshal(entry.Options, &opts); err != nil {
+			return plugins.CollectResult{}, err
+		}
+	}
 	if opts.Limit == 0 {
 		opts.Limit = 5
 	}
@@ -107,4 +111,8 @@ func HotListURLForTest(next string) func() {
 
 func init() {
 	plugins.Register("zhihu", Plugin{})
+	plugins.Describe("zhihu", plugins.Spec{
+		Phases:  []string{config.PhaseCollect},
+		Options: collectOptions{},
+	})
 }
@@ -1,17 +1,193 @@
 package retry
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Policy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Retryable  func(error) bool
+	OnRetry    func(attempt int, delay time.Duration, err error)
+}
+
 func WithRetry[T any](retries int, fn func() (T, error)) (T, error) {
+	return Do(context.Background(), Policy{
+		MaxRetries: retries,
+		Retryable:  func(error) bool { return true },
+	}, func(context.Context) (T, error) {
+		return fn()
+	})
+}
+
+func Do[T any](ctx context.Context, policy Policy, fn func(context.Context) (T, error)) (T, error) {
 	var zero T
-	var lastErr error
-	for i := range retries + 1 {
-		value, err := fn()
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	for attempt := 0; ; attempt++ {
+		value, err := fn(ctx)
 		if err == nil {
 			return value, nil
 		}
-		lastErr = err
-		if i == retries {
-			break
+		if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+			return zero, fmt.Errorf("retry aborted: %w; last error: %w", ctxErr, err)
+		}
+		if attempt >= policy.MaxRetries || ctx.Err() != nil || !retryable(err) {
+			return zero, err
+		}
+		delay := policy.Delay(attempt, RetryAfter(err))
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt+1, delay, err)
+		}
+		if sleepErr := Sleep(ctx, delay); sleepErr != nil {
+			return zero, fmt.Errorf("retry aborted: %w; last error: %w", sleepErr, err)
+		}
+	}
+}
+
+// Delay returns the wait before retry number attempt+1: exponential backoff
+// with equal jitter, never shorter than the server's Retry-After hint, and
+// capped at MaxDelay.
+func (p Policy) Delay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := time.Duration(0)
+	if p.BaseDelay > 0 {
+		backoff := p.BaseDelay << min(attempt, 30)
+		if backoff <= 0 || (p.MaxDelay > 0 && backoff > p.MaxDelay) {
+			backoff = p.MaxDelay
+		}
+		half := backoff / 2
+		delay = half + rand.N(half+1)
+	}
+	delay = max(delay, retryAfter)
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Sleep waits for d or until ctx is done. It fails fast when ctx's deadline
+// would expire before the wait ends.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Body)
+}
+
+func NewStatusError(resp *http.Response) *StatusError {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Body:       strings.TrimSpace(string(data)),
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var permanent permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return RetryableStatus(status.StatusCode)
+	}
+	return true
+}
+
+func RetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return code >= http.StatusInternalServerError && code != http.StatusNotImplemented
+}
+
+func RetryAfter(err error) time.Duration {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.RetryAfter
+	}
+	return 0
+}
+
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait
 		}
 	}
-	return zero, lastErr
+	return 0
 }
@@ -1,8 +1,11 @@
 package retry
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestWithRetry_RetriesAndReturnsLastError(t *testing.T) {
@@ -20,3 +23,114 @@ func TestWithRetry_RetriesAndReturnsLastError(t *testing.T) {
 		t.Fatalf("expected 2 attempts, got %d", attempts)
 	}
 }
+
+func TestDo_StopsOnPermanentError(t *testing.T) {
+	attempts := 0
+	_, err := Do(context.Background(), Policy{MaxRetries: 3}, func(context.Context) (int, error) {
+		attempts++
+		return 0, Permanent(errors.New("bad request"))
+	})
+	if err == nil || err.Error() != "bad request" {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestDo_RetriesRetryableStatusAndReportsRetries(t *testing.T) {
+	attempts := 0
+	var retries []int
+	value, err := Do(context.Background(), Policy{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+		OnRetry: func(attempt int, _ time.Duration, _ error) {
+			retries = append(retries, attempt)
+		},
+	}, func(context.Context) (string, error) {
+		attempts++
+		if attempts < 3 {
+			return "", &StatusError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return "ok", nil
+	})
+	if err != nil || value != "ok" {
+		t.Fatalf("expected success after retries, got %q %v", value, err)
+	}
+	if len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Fatalf("unexpected retry callbacks: %#v", retries)
+	}
+}
+
+func TestDo_DoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	_, err := Do(context.Background(), Policy{MaxRetries: 2}, func(context.Context) (int, error) {
+		attempts++
+		return 0, &StatusError{StatusCode: http.StatusUnauthorized}
+	})
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected a single failed attempt, got %d attempts and %v", attempts, err)
+	}
+}
+
+func TestDo_StopsWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	_, err := Do(ctx, Policy{MaxRetries: 5, BaseDelay: time.Hour}, func(context.Context) (int, error) {
+		attempts++
+		cancel()
+		return 0, errors.New("transient")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestDo_DoesNotSleepPastDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	wantErr := &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}
+
+	start := time.Now()
+	_, err := Do(ctx, Policy{MaxRetries: 1}, func(context.Context) (int, error) {
+		return 0, wantErr
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, wantErr) {
+		t.Fatalf("expected deadline and last error, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("expected retry to give up without waiting for Retry-After")
+	}
+}
+
+func TestPolicyDelay_BacksOffAndHonoursRetryAfter(t *testing.T) {
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		got := policy.Delay(attempt, 0)
+		if got < want/2 || got > want {
+			t.Fatalf("attempt %d: expected delay in [%s, %s], got %s", attempt, want/2, want, got)
+		}
+	}
+	if got := policy.Delay(0, 700*time.Millisecond); got != 700*time.Millisecond {
+		t.Fatalf("expected Retry-After to win, got %s", got)
+	}
+	if got := policy.Delay(0, time.Minute); got != time.Second {
+		t.Fatalf("expected Retry-After to be capped, got %s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if got := ParseRetryAfter("3", now); got != 3*time.Second {
+		t.Fatalf("expected 3s, got %s", got)
+	}
+	if got := ParseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now); got != 90*time.Second {
+		t.Fatalf("expected 90s, got %s", got)
+	}
+	if got := ParseRetryAfter("soon", now); got != 0 {
+		t.Fatalf("expected invalid header to be ignored, got %s", got)
+	}
+}
@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_CronNext(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC)
+	for _, tc := range []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "*/15 * * * *", want: time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{spec: "0 * * * *", want: time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{spec: "30 8 * * 1-5", want: time.Date(2026, 3, 16, 8, 30, 0, 0, time.UTC)},
+		{spec: "0 9 1 * *", want: time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", want: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 6,18 * * *", want: time.Date(2026, 3, 14, 18, 0, 0, 0, time.UTC)},
+		{spec: "0 0 13 * 5", want: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := Parse(tc.spec)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := schedule.Next(base); !got.Equal(tc.want) {
+				t.Fatalf("Next = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse_IntervalAlignsToMultiples(t *testing.T) {
+	for _, spec := range []string{"6h", "@every 6h"} {
+		schedule, err := Parse(spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spec, err)
+		}
+		got := schedule.Next(time.Date(2026, 3, 14, 10, 7, 0, 0, time.UTC))
+		if want := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Fatalf("Next = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestParse_RejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"", "soon", "10s", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Fatalf("expected %q to be rejected", spec)
+		}
+	}
+}
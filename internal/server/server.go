@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/config"
+)
+
+// contentTypes lists the report artifacts the server publishes: feeds, HTML
+// pages, the site index and the OPML list. Everything else in the output
+// directory (history, checkpoints, manifests, usage, the LLM cache) is
+// internal state and answers 404.
+var contentTypes = map[string]string{
+	".xml":  "application/rss+xml; charset=utf-8",
+	".html": "text/html; charset=utf-8",
+	".opml": "text/x-opml; charset=utf-8",
+}
+
+// Server serves generated output files and lets clients trigger source runs.
+type Server struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>" to
+	// trigger a run.
+	Token string
+
+	outputDir string
+	sources   []config.SourceConfig
+	runSource func(ctx context.Context, source config.SourceConfig) error
+	logger    *slog.Logger
+	ctx       context.Context
+
+	mu     sync.Mutex
+	status map[string]*RunStatus
+	wg     sync.WaitGroup
+}
+
+type RunStatus struct {
+	Running    bool       `json:"running"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type sourceInfo struct {
+	Name     string    `json:"name"`
+	Title    string    `json:"title,omitempty"`
+	Schedule string    `json:"schedule,omitempty"`
+	LastRun  RunStatus `json:"lastRun"`
+}
+
+// New returns a Server whose triggered runs use ctx, so cancelling it stops
+// them.
+func New(ctx context.Context, outputDir string, sources []config.SourceConfig, runSource func(context.Context, config.SourceConfig) error, logger *slog.Logger) *Server {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	return &Server{
+		outputDir: outputDir,
+		sources:   sources,
+		runSource: runSource,
+		logger:    logger,
+		ctx:       ctx,
+		status:    map[string]*RunStatus{},
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sources", s.handleSources)
+	mux.HandleFunc("POST /sources/{name}/run", s.handleRun)
+	mux.HandleFunc("GET /", s.handleFile)
+	return mux
+}
+
+// Wait blocks until every triggered run has returned.
+func (s *Server) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Server) handleSources(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	infos := make([]sourceInfo, 0, len(s.sources))
+	for _, source := range s.sources {
+		info := sourceInfo{Name: source.Name, Title: source.Title, Schedule: source.Schedule}
+		if status, ok := s.status[source.Name]; ok {
+			info.LastRun = *status
+		}
+		infos = append(infos, info)
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid token"})
+		return
+	}
+	name := r.PathValue("name")
+	var source *config.SourceConfig
+	for i := range s.sources {
+		if s.sources[i].Name == name {
+			source = &s.sources[i]
+			break
+		}
+	}
+	if source == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("source %q not found", name)})
+		return
+	}
+
+	s.mu.Lock()
+	if status, ok := s.status[name]; ok && status.Running {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("source %q is already running", name)})
+		return
+	}
+	started := time.Now().UTC()
+	status := &RunStatus{Running: true, StartedAt: &started}
+	s.status[name] = status
+	snapshot := *status
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func(source config.SourceConfig) {
+		defer s.wg.Done()
+		err := s.runSource(s.ctx, source)
+		finished := time.Now().UTC()
+		s.mu.Lock()
+		status.Running = false
+		status.FinishedAt = &finished
+		status.Status = "ok"
+		if err != nil {
+			status.Status = "failed"
+			status.Error = err.Error()
+		}
+		s.mu.Unlock()
+		if err != nil {
+			s.logger.Error("triggered run failed", "source", source.Name, "error", err)
+			return
+		}
+		s.logger.Info("triggered run completed", "source", source.Name, "duration", finished.Sub(started))
+	}(*source)
+
+	writeJSON(w, http.StatusAccepted, snapshot)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + r.URL.Path)
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	full := filepath.Join(s.outputDir, filepath.FromSlash(name))
+	info, err := os.Stat(full)
+	if err == nil && info.IsDir() {
+		full = filepath.Join(full, "index.html")
+		info, err = os.Stat(full)
+	}
+	if err != nil || info.IsDir() {
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			s.logger.Warn("stat output file failed", "path", full, "error", err)
+		}
+		http.NotFound(w, r)
+		return
+	}
+	contentType, ok := contentTypes[strings.ToLower(filepath.Ext(full))]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	file, err := os.Open(full)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liuerfire/sieve/internal/config"
+)
+
+func TestServer_ServesOutputWithCachingHeaders(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hacker-news.xml"), []byte("<rss></rss>"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	for _, name := range []string{".secret", "hacker-news-checkpoint.json", "hacker-news-processed.json", "hacker-news-llm-usage.json", "llm-cache/ab/cd.json"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	srv := httptest.NewServer(New(context.Background(), dir, nil, nil, nil).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/hacker-news.xml")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/rss+xml; charset=utf-8" {
+		t.Fatalf("unexpected response: %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Last-Modified") == "" {
+		t.Fatalf("expected ETag and Last-Modified, got %v", resp.Header)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/hacker-news.xml", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "<html></html>" {
+		t.Fatalf("expected index.html at root, got %d %q", resp.StatusCode, body)
+	}
+
+	for _, path := range []string{"/.secret", "/missing.xml", "/hacker-news-checkpoint.json", "/hacker-news-processed.json", "/hacker-news-llm-usage.json", "/llm-cache/ab/cd.json", "/llm-cache/"} {
+		resp, err = http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_TriggersRunsAndReportsStatus(t *testing.T) {
+	release := make(chan struct{})
+	sources := []config.SourceConfig{{Name: "good", Title: "Good"}, {Name: "bad"}}
+	s := New(context.Background(), t.TempDir(), sources, func(_ context.Context, source config.SourceConfig) error {
+		<-release
+		if source.Name == "bad" {
+			return errors.New("collect failed")
+		}
+		return nil
+	}, nil)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	post := func(name string) int {
+		resp, err := http.Post(srv.URL+"/sources/"+name+"/run", "application/json", nil)
+		if err != nil {
+			t.Fatalf("Post: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := post("good"); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	if code := post("good"); code != http.StatusConflict {
+		t.Fatalf("expected 409 while running, got %d", code)
+	}
+	if code := post("bad"); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	if code := post("missing"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown source, got %d", code)
+	}
+	close(release)
+	s.Wait()
+
+	resp, err := http.Get(srv.URL + "/sources")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	var infos []sourceInfo
+	if err := json.NewDecoder(resp.Body).Decode(&infos); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(infos) != 2 || infos[0].Title != "Good" {
+		t.Fatalf("unexpected sources: %#v", infos)
+	}
+	if infos[0].LastRun.Status != "ok" || infos[0].LastRun.Running || infos[0].LastRun.FinishedAt == nil {
+		t.Fatalf("unexpected good status: %#v", infos[0].LastRun)
+	}
+	if infos[1].LastRun.Status != "failed" || !strings.Contains(infos[1].LastRun.Error, "collect failed") {
+		t.Fatalf("unexpected bad status: %#v", infos[1].LastRun)
+	}
+}
+
+func TestServer_RequiresTokenToTriggerRuns(t *testing.T) {
+	runs := 0
+	s := New(context.Background(), t.TempDir(), []config.SourceConfig{{Name: "good"}}, func(context.Context, config.SourceConfig) error {
+		runs++
+		return nil
+	}, nil)
+	s.Token = "secret"
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	post := func(authorization string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/sources/good/run", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		_ = resp.Body.Close()
+		s.Wait()
+		return resp.StatusCode
+	}
+	for _, authorization := range []string{"", "Bearer wrong", "secret"} {
+		if code := post(authorization); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for %q, got %d", authorization, code)
+		}
+	}
+	if runs != 0 {
+		t.Fatalf("expected no runs without the token, got %d", runs)
+	}
+	if code := post("Bearer secret"); code != http.StatusAccepted || runs != 1 {
+		t.Fatalf("expected the token to start a run, got %d with %d runs", code, runs)
+	}
+}
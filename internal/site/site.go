@@ -0,0 +1,352 @@
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/types"
+	"github.com/liuerfire/sieve/internal/workflow"
+)
+
+const (
+	IndexFile = "index.html"
+	OPMLFile  = "feeds.opml"
+)
+
+type Options struct {
+	OutputDir string
+	// BaseURL, when set, makes OPML feed links absolute so the file can be
+	// imported into a reader.
+	BaseURL string
+	Now     time.Time
+}
+
+// Entry describes one source on the landing page. LastRun is the status of
+// the source's last run, empty when there is no manifest. The level counts
+// cover the items of the last OK run, which is what the feed still shows
+// after a failed one; Counted reports whether there was such a run.
+type Entry struct {
+	Name        string
+	Title       string
+	HTMLPath    string
+	RSSPath     string
+	Updated     time.Time
+	LastRun     string
+	Counted     bool
+	Critical    int
+	Recommended int
+	Optional    int
+}
+
+func (e Entry) Total() int {
+	return e.Critical + e.Recommended + e.Optional
+}
+
+// Build writes the landing page and a combined OPML file for every source
+// that has generated output under opts.OutputDir.
+func Build(cfg *config.Config, opts Options) ([]Entry, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	entries := make([]Entry, 0, len(cfg.Sources))
+	for _, source := range cfg.Sources {
+		entry, ok, err := sourceEntry(cfg, source, opts.OutputDir)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := os.MkdirAll(opts.OutputDir, 0o755); err != nil {
+		return nil, err
+	}
+	var page bytes.Buffer
+	if err := indexTemplate.Execute(&page, indexData{Entries: entries, Generated: opts.Now.UTC()}); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(opts.OutputDir, IndexFile), page.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+	opml, err := buildOPML(entries, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(opts.OutputDir, OPMLFile), opml, 0o644); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func sourceEntry(cfg *config.Config, source config.SourceConfig, outputDir string) (Entry, bool, error) {
+	entry := Entry{Name: source.Name, Title: source.Title}
+	rssPath := reporterOutput(cfg, source, "builtin/reporter-rss", filepath.Join(outputDir, source.Name+".xml"))
+	htmlPath := reporterOutput(cfg, source, "builtin/reporter-html", filepath.Join(outputDir, source.Name+".html"))
+
+	if rel, ok := existingRel(outputDir, htmlPath); ok {
+		entry.HTMLPath = rel
+		if info, err := os.Stat(htmlPath); err == nil {
+			entry.Updated = info.ModTime()
+		}
+	}
+	if rel, ok := existingRel(outputDir, rssPath); ok {
+		entry.RSSPath = rel
+		feed, err := readFeed(rssPath)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("read %s: %w", rssPath, err)
+		}
+		if entry.Title == "" {
+			entry.Title = feed.Channel.Title
+		}
+		if updated, err := time.Parse(time.RFC1123Z, feed.Channel.LastBuildDate); err == nil {
+			entry.Updated = updated
+		} else if info, err := os.Stat(rssPath); err == nil && entry.Updated.IsZero() {
+			entry.Updated = info.ModTime()
+		}
+	}
+	if entry.HTMLPath == "" && entry.RSSPath == "" {
+		return entry, false, nil
+	}
+	if entry.Title == "" {
+		entry.Title = source.Name
+	}
+	if err := countLevels(&entry, outputDir, source.Name); err != nil {
+		return Entry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// countLevels records the last run's status and fills in the level counts
+// from the last OK run's manifest.
+func countLevels(entry *Entry, outputDir string, source string) error {
+	last, ok, err := readManifest(workflow.ManifestPath(outputDir, source))
+	if err != nil || !ok {
+		return err
+	}
+	entry.LastRun = last.Status
+	if !last.OK() {
+		if last, ok, err = readManifest(workflow.LastOKManifestPath(outputDir, source)); err != nil || !ok {
+			return err
+		}
+	}
+	entry.Counted = true
+	for _, item := range last.Items {
+		switch item.Level {
+		case types.LevelCritical:
+			entry.Critical++
+		case types.LevelRecommended:
+			entry.Recommended++
+		case types.LevelRejected:
+		default:
+			entry.Optional++
+		}
+	}
+	return nil
+}
+
+func readManifest(path string) (workflow.Manifest, bool, error) {
+	manifest, err := workflow.ReadManifest(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, false, nil
+	}
+	if err != nil {
+		return manifest, false, fmt.Errorf("read %s: %w", path, err)
+	}
+	return manifest, true, nil
+}
+
+// reporterOutput returns the outputPath a reporter writes to for source,
+// falling back to the global plugin options and then to fallback.
+func reporterOutput(cfg *config.Config, source config.SourceConfig, plugin, fallback string) string {
+	var opts struct {
+		OutputPath string `json:"outputPath"`
+	}
+	for _, entry := range source.Plugins {
+		if entry.Name != plugin {
+			continue
+		}
+		if len(entry.Options) > 0 && json.Unmarshal(entry.Options, &opts) == nil && opts.OutputPath != "" {
+			return opts.OutputPath
+		}
+		if global := cfg.Plugins[plugin]; len(global) > 0 && json.Unmarshal(global, &opts) == nil && opts.OutputPath != "" {
+			return opts.OutputPath
+		}
+	}
+	return fallback
+}
+
+func existingRel(outputDir, path string) (string, bool) {
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(outputDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+type feedFile struct {
+	Channel struct {
+		Title         string `xml:"title"`
+		LastBuildDate string `xml:"lastBuildDate"`
+	} `xml:"channel"`
+}
+
+func readFeed(path string) (feedFile, error) {
+	var feed feedFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return feed, err
+	}
+	err = xml.Unmarshal(data, &feed)
+	return feed, err
+}
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Created string        `xml:"head>dateCreated"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Type    string `xml:"type,attr"`
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+}
+
+func buildOPML(entries []Entry, opts Options) ([]byte, error) {
+	doc := opmlDocument{Version: "2.0", Title: "Sieve feeds", Created: opts.Now.UTC().Format(time.RFC1123Z)}
+	for _, entry := range entries {
+		if entry.RSSPath == "" {
+			continue
+		}
+		outline := opmlOutline{
+			Type:   "rss",
+			Text:   entry.Title,
+			Title:  entry.Title,
+			XMLURL: siteURL(opts.BaseURL, entry.RSSPath),
+		}
+		if entry.HTMLPath != "" {
+			outline.HTMLURL = siteURL(opts.BaseURL, entry.HTMLPath)
+		}
+		doc.Body = append(doc.Body, outline)
+	}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func siteURL(base, rel string) string {
+	if base == "" {
+		return rel
+	}
+	return strings.TrimRight(base, "/") + "/" + rel
+}
+
+type indexData struct {
+	Entries   []Entry
+	Generated time.Time
+}
+
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>Sieve Output</title>
+  <link rel="alternate" type="text/x-opml" title="All feeds" href="` + OPMLFile + `" />
+  <style>
+    body {
+      margin: 0;
+      font-family: "Avenir Next", "Segoe UI", sans-serif;
+      color: #1f1a17;
+      background: linear-gradient(180deg, #faf4eb 0%, #efe5d6 100%);
+    }
+    main {
+      width: min(960px, calc(100vw - 32px));
+      margin: 0 auto;
+      padding: 40px 0 72px;
+    }
+    h1 {
+      margin: 0 0 10px;
+      font-size: clamp(36px, 7vw, 72px);
+      letter-spacing: -0.05em;
+    }
+    p {
+      color: #6b6259;
+    }
+    .grid {
+      display: grid;
+      gap: 16px;
+      margin-top: 28px;
+    }
+    article {
+      padding: 20px 22px;
+      border-radius: 22px;
+      background: rgba(255, 250, 242, 0.88);
+      border: 1px solid rgba(72, 53, 33, 0.1);
+      box-shadow: 0 18px 40px rgba(56, 38, 20, 0.08);
+    }
+    h2 {
+      margin: 0 0 8px;
+      font-size: 24px;
+    }
+    a {
+      color: #7c3f15;
+      text-decoration: none;
+      font-weight: 600;
+    }
+    .meta {
+      font-size: 14px;
+    }
+  </style>
+</head>
+<body>
+  <main>
+    <h1>Sieve Output</h1>
+    <p>Generated {{ datetime .Generated }} from the configured sources. <a href="` + OPMLFile + `">OPML</a></p>
+    <section class="grid">
+      {{- range .Entries }}
+      <article>
+        <h2>{{ .Title }}</h2>
+        <p>{{ .Name }}</p>
+        <div>
+          {{- if .HTMLPath }}<a href="{{ .HTMLPath }}">HTML</a>{{ end }}
+          {{- if and .HTMLPath .RSSPath }} · {{ end }}
+          {{- if .RSSPath }}<a href="{{ .RSSPath }}">RSS</a>{{ end -}}
+        </div>
+        <p class="meta">
+          {{- if not .Updated.IsZero }}Updated {{ datetime .Updated }}{{ end -}}
+          {{- if and (not .Updated.IsZero) .LastRun }} · {{ end -}}
+          {{- if and .LastRun (ne .LastRun "ok") }}Last run {{ .LastRun }}{{ if .Counted }} · {{ end }}{{ end -}}
+          {{- if .Counted }}Last published run had {{ .Total }} items: {{ .Critical }} critical, {{ .Recommended }} recommended, {{ .Optional }} optional{{ end -}}
+        </p>
+      </article>
+      {{- else }}
+      <p>No generated content yet.</p>
+      {{- end }}
+    </section>
+  </main>
+</body>
+</html>
+`))
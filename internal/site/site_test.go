@@ -0,0 +1,109 @@
+package site
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/types"
+	"github.com/liuerfire/sieve/internal/workflow"
+)
+
+func writeManifest(t *testing.T, path string, manifest workflow.Manifest) {
+	t.Helper()
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel>
+  <title>Feed Title</title>
+  <lastBuildDate>Sat, 14 Mar 2026 10:00:00 +0000</lastBuildDate>
+  <item><title>Older item from an earlier run</title></item>
+</channel></rss>`
+
+func TestBuild_RendersIndexAndOPML(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "news.xml"), []byte(testFeed), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "news.html"), []byte("<html></html>"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "custom"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "custom", "feed.xml"), []byte(testFeed), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	writeManifest(t, workflow.ManifestPath(dir, "news"), workflow.Manifest{Source: "news", Status: "ok", Items: []workflow.ItemRecord{
+		{GUID: "a", Title: "Critical", Level: types.LevelCritical},
+		{GUID: "b", Title: "Recommended", Level: types.LevelRecommended},
+		{GUID: "c", Title: "Recommended again", Level: types.LevelRecommended},
+		{GUID: "d", Title: "Optional", Level: types.LevelOptional},
+		{GUID: "e", Title: "Rejected", Level: types.LevelRejected},
+	}})
+	writeManifest(t, workflow.ManifestPath(dir, "global"), workflow.Manifest{Source: "global", Status: "failed", Items: []workflow.ItemRecord{
+		{GUID: "f", Title: "Unpublished", Level: types.LevelCritical},
+		{GUID: "g", Title: "Unpublished", Level: types.LevelCritical},
+	}})
+	writeManifest(t, workflow.LastOKManifestPath(dir, "global"), workflow.Manifest{Source: "global", Status: "ok", Items: []workflow.ItemRecord{
+		{GUID: "h", Title: "Still published", Level: types.LevelRecommended},
+	}})
+
+	cfg := &config.Config{
+		Plugins: map[string]json.RawMessage{
+			"builtin/reporter-rss": json.RawMessage(`{"outputPath": "` + filepath.Join(dir, "custom", "feed.xml") + `"}`),
+		},
+		Sources: []config.SourceConfig{
+			{Name: "news", Title: "News & <Views>", Plugins: []config.PluginEntry{{Name: "builtin/reporter-rss", Options: json.RawMessage(`{"outputPath": "` + filepath.Join(dir, "news.xml") + `"}`)}}},
+			{Name: "global", Plugins: []config.PluginEntry{{Name: "builtin/reporter-rss"}}},
+			{Name: "empty", Plugins: []config.PluginEntry{{Name: "builtin/reporter-html"}}},
+		},
+	}
+	entries, err := Build(cfg, Options{OutputDir: dir, BaseURL: "https://example.com/feeds/", Now: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected sources without output to be skipped, got %#v", entries)
+	}
+	news := entries[0]
+	if news.HTMLPath != "news.html" || news.RSSPath != "news.xml" || news.Critical != 1 || news.Recommended != 2 || news.Optional != 1 {
+		t.Fatalf("unexpected news entry: %#v", news)
+	}
+	if !news.Updated.Equal(time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected lastBuildDate as updated time, got %s", news.Updated)
+	}
+	if entries[1].RSSPath != "custom/feed.xml" || entries[1].Title != "Feed Title" || entries[1].LastRun != "failed" || entries[1].Recommended != 1 || entries[1].Total() != 1 {
+		t.Fatalf("expected global outputPath and feed title, got %#v", entries[1])
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, want := range []string{"News &amp; &lt;Views&gt;", `href="news.html"`, `href="custom/feed.xml"`, "Updated 2026-03-14 10:00 UTC", "Last published run had 4 items: 1 critical, 2 recommended, 1 optional", "Last run failed · Last published run had 1 items: 0 critical, 1 recommended, 0 optional"} {
+		if !strings.Contains(string(page), want) {
+			t.Fatalf("expected %q in index page:\n%s", want, page)
+		}
+	}
+
+	opml, err := os.ReadFile(filepath.Join(dir, OPMLFile))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(opml), `xmlUrl="https://example.com/feeds/news.xml"`) || !strings.Contains(string(opml), `htmlUrl="https://example.com/feeds/news.html"`) {
+		t.Fatalf("unexpected opml:\n%s", opml)
+	}
+}
@@ -16,6 +16,47 @@ type guidHistory struct {
 	UpdatedAt string            `json:"updated_at"`
 }
 
+// ProcessedPath is where a source's deduplication history is stored.
+func ProcessedPath(source string) string {
+	return filepath.Join("output", source+"-processed.json")
+}
+
+// PendingPath is where deduplication keeps the GUIDs a run found new until
+// the run commits them to the history. It lives on disk so a resumed run,
+// which skips deduplication, still commits them.
+func PendingPath(source string) string {
+	return filepath.Join("output", source+"-dedup-pending.json")
+}
+
+// SavePending replaces the pending GUIDs at path.
+func SavePending(path string, guids []string) error {
+	data, err := json.Marshal(guids)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadPending returns the pending GUIDs at path, or none when there is no
+// pending file.
+func LoadPending(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var guids []string
+	if err := json.Unmarshal(data, &guids); err != nil {
+		return nil, fmt.Errorf("invalid pending guids %q: %w", path, err)
+	}
+	return guids, nil
+}
+
 type GUIDTracker struct {
 	historyPath string
 	processed   map[string]string
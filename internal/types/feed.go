@@ -19,6 +19,11 @@ type FeedItem struct {
 	Extra       map[string]any `json:"extra"`
 	Level       FeedLevel      `json:"level"`
 	Reason      string         `json:"reason"`
+	// GradeBackend and SummaryBackend name the LLM backends that graded and
+	// summarized the item. They are kept out of Extra, which goes into
+	// prompts.
+	GradeBackend   string `json:"gradeBackend,omitempty"`
+	SummaryBackend string `json:"summaryBackend,omitempty"`
 }
 
 func (i FeedItem) WithDefaults() FeedItem {
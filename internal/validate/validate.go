@@ -0,0 +1,221 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/llm"
+	"github.com/liuerfire/sieve/internal/plugins"
+	"github.com/liuerfire/sieve/internal/workflow"
+)
+
+// Problem is one thing that would make a run fail. Source and Plugin are
+// empty for problems that are not tied to one.
+type Problem struct {
+	Source  string
+	Plugin  string
+	Message string
+}
+
+func (p Problem) String() string {
+	switch {
+	case p.Source != "" && p.Plugin != "":
+		return fmt.Sprintf("source %q: plugin %q: %s", p.Source, p.Plugin, p.Message)
+	case p.Source != "":
+		return fmt.Sprintf("source %q: %s", p.Source, p.Message)
+	default:
+		return p.Message
+	}
+}
+
+type Options struct {
+	// LLM builds the provider for a model tier the way a run would. Nil
+	// skips the LLM checks.
+	LLM func(tier string) (llm.Provider, error)
+	// OutputDir is where runs keep history, checkpoints and manifests.
+	OutputDir string
+}
+
+// Config checks everything about cfg that can be checked without network
+// calls: cfg.Validate passes, every plugin resolves, required options and
+// environment variables are set, entries only ask for phases their plugin
+// implements, output paths are writable and the LLM tiers in use can be
+// built. It returns every problem found rather than stopping at the first.
+func Config(cfg *config.Config, opts Options) []Problem {
+	var problems []Problem
+	if err := cfg.Validate(); err != nil {
+		for _, err := range unjoin(err) {
+			problems = append(problems, Problem{Message: err.Error()})
+		}
+	}
+	if opts.OutputDir != "" {
+		if err := writableDir(opts.OutputDir); err != nil {
+			problems = append(problems, Problem{Message: fmt.Sprintf("output directory %q is not writable: %v", opts.OutputDir, err)})
+		}
+	}
+
+	tiers := map[string][]string{}
+	for _, source := range cfg.Sources {
+		prefix, entries, err := workflow.Entries(source, cfg.Plugins)
+		if err != nil {
+			problems = append(problems, Problem{Source: source.Name, Message: err.Error()})
+			continue
+		}
+		for _, entry := range prefix {
+			entry.Phases = []string{config.PhaseProcess}
+			problems = append(problems, checkEntry(source.Name, entry, tiers)...)
+		}
+		for _, entry := range entries {
+			problems = append(problems, checkEntry(source.Name, entry, tiers)...)
+		}
+	}
+
+	if opts.LLM != nil {
+		for _, tier := range slices.Sorted(maps.Keys(tiers)) {
+			if _, err := opts.LLM(tier); err != nil {
+				problems = append(problems, Problem{Message: fmt.Sprintf("llm tier %q used by %s: %v", tier, strings.Join(tiers[tier], ", "), err)})
+			}
+		}
+	}
+	return dedupe(problems)
+}
+
+// unjoin splits an errors.Join result back into its errors.
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// dedupe drops problems already reported, since config validation and the
+// per-entry checks can both trip over the same bad options.
+func dedupe(problems []Problem) []Problem {
+	seen := map[string]bool{}
+	unique := problems[:0]
+	for _, problem := range problems {
+		if seen[problem.String()] {
+			continue
+		}
+		seen[problem.String()] = true
+		unique = append(unique, problem)
+	}
+	return unique
+}
+
+func checkEntry(source string, entry config.PluginEntry, tiers map[string][]string) []Problem {
+	var problems []Problem
+	report := func(format string, args ...any) {
+		problems = append(problems, Problem{Source: source, Plugin: entry.Name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if _, err := plugins.Load([]config.PluginEntry{entry}); err != nil {
+		report("%v", err)
+		return problems
+	}
+	spec, ok := plugins.Lookup(entry.Name)
+	if !ok {
+		return problems
+	}
+
+	for _, phase := range entry.Phases {
+		if !slices.Contains(spec.Phases, phase) {
+			report("does nothing in the %s phase; it implements %s", phase, strings.Join(spec.Phases, ", "))
+		}
+	}
+	for _, name := range spec.Env {
+		if os.Getenv(name) == "" {
+			report("environment variable %s is not set", name)
+		}
+	}
+	for _, tier := range spec.LLMTiers {
+		if !slices.Contains(tiers[tier], source) {
+			tiers[tier] = append(tiers[tier], source)
+		}
+	}
+
+	schema, ok := config.LookupOptions(entry.Name)
+	if !ok {
+		return problems
+	}
+	missing, err := schema.Missing(entry.Options)
+	if err != nil {
+		report("invalid options: %v", err)
+		return problems
+	}
+	for _, name := range missing {
+		report("missing required option %q", name)
+	}
+	var values map[string]any
+	if len(entry.Options) > 0 {
+		if err := json.Unmarshal(entry.Options, &values); err != nil {
+			report("invalid options: %v", err)
+			return problems
+		}
+	}
+	for _, field := range schema.Fields() {
+		path, _ := values[field.Name].(string)
+		if !field.Path || path == "" {
+			continue
+		}
+		if err := writable(path); err != nil {
+			report("option %q: %s is not writable: %v", field.Name, path, err)
+		}
+	}
+	return problems
+}
+
+// writable reports whether path can be created or overwritten. Missing
+// parent directories are fine as long as they could be created.
+func writable(path string) error {
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return fmt.Errorf("is a directory")
+	case err == nil:
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	return writableDir(filepath.Dir(path))
+}
+
+// writableDir checks that a file can be created in dir, or in its closest
+// existing ancestor when dir does not exist yet. It leaves nothing behind.
+func writableDir(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+	probe, err := os.CreateTemp(dir, ".sieve-validate-*")
+	if err != nil {
+		return err
+	}
+	_ = probe.Close()
+	return os.Remove(probe.Name())
+}
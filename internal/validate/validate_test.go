@@ -0,0 +1,134 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/llm"
+	_ "github.com/liuerfire/sieve/internal/plugins/builtin"
+	_ "github.com/liuerfire/sieve/internal/plugins/producthunt"
+)
+
+var testLLM = config.LLMConfig{
+	Provider: "openai",
+	Models: config.LLMModels{
+		Fast:     config.LLMTier{Model: "fast"},
+		Balanced: config.LLMTier{Model: "balanced"},
+		Powerful: config.LLMTier{Model: "powerful"},
+	},
+}
+
+func TestConfig_ReportsEveryProblem(t *testing.T) {
+	t.Setenv("PRODUCTHUNT_API_KEY", "")
+	dir := t.TempDir()
+	cfg := &config.Config{
+		LLM: testLLM,
+		Plugins: map[string]json.RawMessage{
+			"builtin/reporter-rss": json.RawMessage(`{"outputPath": "` + dir + `"}`),
+		},
+		Sources: []config.SourceConfig{
+			{Name: "news", Plugins: []config.PluginEntry{
+				{Name: "builtin/collect-rss"},
+				{Name: "builtin/llm-grade"},
+				{Name: "builtin/reporter-rss"},
+			}},
+			{Name: "launches", Plugins: []config.PluginEntry{
+				{Name: "producthunt"},
+				{Name: "builtin/clean-text", Phases: []string{config.PhaseReport}},
+				{Name: "missing/plugin"},
+			}},
+		},
+	}
+
+	problems := Config(cfg, Options{
+		LLM: func(tier string) (llm.Provider, error) {
+			return nil, errors.New("API key not set")
+		},
+	})
+
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.String())
+	}
+	want := []string{
+		`source "news": plugin "builtin/collect-rss": missing required option "url"`,
+		`source "news": plugin "builtin/reporter-rss": option "outputPath": ` + dir + ` is not writable: is a directory`,
+		`source "launches": plugin "producthunt": environment variable PRODUCTHUNT_API_KEY is not set`,
+		`source "launches": plugin "builtin/clean-text": does nothing in the report phase; it implements process`,
+		`source "launches": plugin "missing/plugin": plugin "missing/plugin" not found`,
+		`llm tier "balanced" used by news: API key not set`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected problems:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestConfig_AcceptsValidConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		LLM: testLLM,
+		Sources: []config.SourceConfig{
+			{Name: "news", Plugins: []config.PluginEntry{
+				{Name: "builtin/collect-rss", Options: json.RawMessage(`{"url": "https://example.com/feed"}`)},
+				{Name: "builtin/reporter-rss", Options: json.RawMessage(`{"outputPath": "` + filepath.Join(dir, "feeds", "news.xml") + `"}`)},
+			}},
+		},
+	}
+
+	if problems := Config(cfg, Options{OutputDir: filepath.Join(dir, "output")}); len(problems) != 0 {
+		t.Fatalf("expected no problems, got %v", problems)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected validation to leave nothing behind, got %v", entries)
+	}
+}
+
+func TestConfig_ReportsUnwritableOutputDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		LLM:     testLLM,
+		Sources: []config.SourceConfig{{Name: "news", Plugins: []config.PluginEntry{{Name: "builtin/clean-text"}}}},
+	}
+	problems := Config(cfg, Options{OutputDir: file})
+	if len(problems) != 1 || !strings.Contains(problems[0].String(), "is not a directory") {
+		t.Fatalf("expected output dir problem, got %v", problems)
+	}
+}
+
+func TestConfig_IncludesConfigValidationErrors(t *testing.T) {
+	cfg := &config.Config{
+		LLM: testLLM,
+		Sources: []config.SourceConfig{
+			{Name: "news", Plugins: []config.PluginEntry{
+				{Name: "builtin/collect-rss", Options: json.RawMessage(`{"url": "https://example.com/feed", "maxitems": 5}`)},
+				{Name: "builtin/clean-text", OnError: "sometimes"},
+			}},
+		},
+	}
+
+	var got []string
+	for _, problem := range Config(cfg, Options{}) {
+		got = append(got, problem.String())
+	}
+	want := []string{
+		`source[0].plugins[1].onError: unsupported onError "sometimes": want "fail", "skip" or "retry:N"`,
+		`source "news": plugin "builtin/collect-rss": unknown option "maxitems"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected problems:\ngot  %q\nwant %q", got, want)
+	}
+}
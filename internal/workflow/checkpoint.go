@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/plugins"
+	"github.com/liuerfire/sieve/internal/types"
+)
+
+// checkpoint is the working item set after the last completed stage.
+// Completed lists the process plugins that have run, in pipeline order; it is
+// empty right after collection.
+type checkpoint struct {
+	Source    string           `json:"source"`
+	Title     string           `json:"title,omitempty"`
+	Completed []string         `json:"completed"`
+	Items     []types.FeedItem `json:"items"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+func CheckpointPath(outputDir string, source string) string {
+	return filepath.Join(outputDir, source+"-checkpoint.json")
+}
+
+func saveCheckpoint(params Params, cp checkpoint) error {
+	if params.IsDryRun {
+		return nil
+	}
+	cp.Source = params.SourceName
+	cp.UpdatedAt = time.Now().UTC()
+	if cp.Completed == nil {
+		cp.Completed = []string{}
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	path := CheckpointPath("output", params.SourceName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// resumeCheckpoint returns the checkpoint to resume from, or nil when the
+// run should start from scratch. A checkpoint whose completed plugins are not
+// a prefix of the current pipeline is ignored.
+func resumeCheckpoint(params Params, pipeline []plugins.LoadedPlugin) (*checkpoint, error) {
+	if !params.Resume {
+		return nil, nil
+	}
+	path := CheckpointPath("output", params.SourceName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		logInfo(params.Logger, "no checkpoint found, starting from scratch", "source", params.SourceName)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %q: %w", path, err)
+	}
+	names := pluginNames(pipeline)
+	if len(cp.Completed) > len(names) || !slices.Equal(cp.Completed, names[:len(cp.Completed)]) {
+		logWarn(params.Logger, "checkpoint does not match the pipeline, starting from scratch", "source", params.SourceName, "checkpoint", path)
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+func clearCheckpoint(params Params) error {
+	if params.IsDryRun {
+		return nil
+	}
+	err := os.Remove(CheckpointPath("output", params.SourceName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func pluginNames(loaded []plugins.LoadedPlugin) []string {
+	names := make([]string, 0, len(loaded))
+	for _, plugin := range loaded {
+		names = append(names, plugin.Name)
+	}
+	return names
+}
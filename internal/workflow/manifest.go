@@ -0,0 +1,256 @@
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liuerfire/sieve/internal/config"
+	"github.com/liuerfire/sieve/internal/llm"
+	"github.com/liuerfire/sieve/internal/types"
+)
+
+// Manifest is the machine-readable record of one run, written to
+// output/<source>-run.json.
+type Manifest struct {
+	Source       string           `json:"source"`
+	Title        string           `json:"title,omitempty"`
+	ResumedAfter string           `json:"resumedAfter,omitempty"`
+	Status       string           `json:"status"`
+	Error        string           `json:"error,omitempty"`
+	StartedAt    time.Time        `json:"startedAt"`
+	FinishedAt   time.Time        `json:"finishedAt"`
+	Duration     config.Duration  `json:"duration"`
+	Counts       ItemCounts       `json:"counts"`
+	Stages       []StageRecord    `json:"stages"`
+	Items        []ItemRecord     `json:"items"`
+	Errors       []ErrorRecord    `json:"errors,omitempty"`
+	LLMUsage     *llm.UsageReport `json:"llmUsage,omitempty"`
+}
+
+type ItemCounts struct {
+	Total    int `json:"total"`
+	Visible  int `json:"visible"`
+	Rejected int `json:"rejected"`
+}
+
+// StageRecord covers one plugin call in one stage. ItemsBefore and
+// ItemsAfter are the size of the working set around the call.
+type StageRecord struct {
+	Stage       string          `json:"stage"`
+	Plugin      string          `json:"plugin"`
+	Status      string          `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	StartedAt   time.Time       `json:"startedAt"`
+	Duration    config.Duration `json:"duration"`
+	ItemsBefore int             `json:"itemsBefore"`
+	ItemsAfter  int             `json:"itemsAfter"`
+}
+
+type ItemRecord struct {
+	GUID           string            `json:"guid"`
+	Title          string            `json:"title"`
+	Level          types.FeedLevel   `json:"level"`
+	GradeBackend   string            `json:"gradeBackend,omitempty"`
+	SummaryBackend string            `json:"summaryBackend,omitempty"`
+	Transitions    []LevelTransition `json:"transitions,omitempty"`
+}
+
+// LevelTransition records a plugin changing an item's level. From is empty
+// when the item arrived with a level already set by its collector.
+type LevelTransition struct {
+	Plugin string          `json:"plugin"`
+	From   types.FeedLevel `json:"from,omitempty"`
+	To     types.FeedLevel `json:"to"`
+}
+
+// ErrorRecord is a plugin failure the run tolerated: a retried attempt or a
+// skipped plugin.
+type ErrorRecord struct {
+	Stage   string `json:"stage"`
+	Plugin  string `json:"plugin"`
+	Attempt int    `json:"attempt,omitempty"`
+	Error   string `json:"error"`
+}
+
+const (
+	statusOK      = "ok"
+	statusSkipped = "skipped"
+	statusFailed  = "failed"
+	// statusIncomplete marks a run that finished but had reporters skipped
+	// by onError, so its items were not all published and nothing was
+	// committed.
+	statusIncomplete = "incomplete"
+)
+
+type manifestRecorder struct {
+	mu             sync.Mutex
+	manifest       Manifest
+	items          []types.FeedItem
+	transitions    map[string][]LevelTransition
+	skippedReports []string
+}
+
+func newManifestRecorder(source string) *manifestRecorder {
+	return &manifestRecorder{
+		manifest:    Manifest{Source: source, StartedAt: time.Now().UTC()},
+		transitions: map[string][]LevelTransition{},
+	}
+}
+
+func (r *manifestRecorder) stage(stage string, plugin string, started time.Time, before int, after int, skipped bool, err error) {
+	record := StageRecord{
+		Stage:       stage,
+		Plugin:      plugin,
+		Status:      statusOK,
+		StartedAt:   started.UTC(),
+		Duration:    config.Duration(time.Since(started)),
+		ItemsBefore: before,
+		ItemsAfter:  after,
+	}
+	switch {
+	case err != nil:
+		record.Status = statusFailed
+		record.Error = err.Error()
+	case skipped:
+		record.Status = statusSkipped
+	}
+	r.mu.Lock()
+	r.manifest.Stages = append(r.manifest.Stages, record)
+	r.mu.Unlock()
+}
+
+func (r *manifestRecorder) swallowed(stage string, plugin string, attempt int, err error) {
+	r.mu.Lock()
+	r.manifest.Errors = append(r.manifest.Errors, ErrorRecord{Stage: stage, Plugin: plugin, Attempt: attempt, Error: err.Error()})
+	r.mu.Unlock()
+}
+
+// levels records the level changes plugin made going from the current
+// working set to items, which becomes the new working set.
+func (r *manifestRecorder) levels(plugin string, items []types.FeedItem) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before := make(map[string]types.FeedLevel, len(r.items))
+	for _, item := range r.items {
+		before[item.GUID] = item.Level
+	}
+	for _, item := range items {
+		from, seen := before[item.GUID]
+		if from == item.Level || (!seen && item.Level == types.LevelUnknown) {
+			continue
+		}
+		r.transitions[item.GUID] = append(r.transitions[item.GUID], LevelTransition{Plugin: plugin, From: from, To: item.Level})
+	}
+	r.items = items
+}
+
+// reportsSkipped marks the run incomplete because reporters were skipped.
+func (r *manifestRecorder) reportsSkipped(names []string) {
+	r.mu.Lock()
+	r.skippedReports = names
+	r.mu.Unlock()
+}
+
+func (r *manifestRecorder) resume(cp *checkpoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifest.ResumedAfter = stageCollect
+	if len(cp.Completed) > 0 {
+		r.manifest.ResumedAfter = cp.Completed[len(cp.Completed)-1]
+	}
+	r.items = cp.Items
+}
+
+func (r *manifestRecorder) finish(title string, usage llm.UsageReport, err error) Manifest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.manifest
+	m.Title = title
+	m.FinishedAt = time.Now().UTC()
+	m.Duration = config.Duration(m.FinishedAt.Sub(m.StartedAt))
+	switch {
+	case err != nil:
+		m.Status = statusFailed
+		m.Error = err.Error()
+	case len(r.skippedReports) > 0:
+		m.Status = statusIncomplete
+		m.Error = "reporters skipped, commit held back: " + strings.Join(r.skippedReports, ", ")
+	default:
+		m.Status = statusOK
+	}
+	if usage.Total.Calls > 0 {
+		m.LLMUsage = &usage
+	}
+	m.Items = make([]ItemRecord, 0, len(r.items))
+	for _, item := range r.items {
+		m.Counts.Total++
+		if item.Level == types.LevelRejected {
+			m.Counts.Rejected++
+		} else {
+			m.Counts.Visible++
+		}
+		m.Items = append(m.Items, ItemRecord{
+			GUID:           item.GUID,
+			Title:          item.Title,
+			Level:          item.Level,
+			GradeBackend:   item.GradeBackend,
+			SummaryBackend: item.SummaryBackend,
+			Transitions:    r.transitions[item.GUID],
+		})
+	}
+	return m
+}
+
+// OK reports whether the run completed and every reporter published its
+// items.
+func (m Manifest) OK() bool {
+	return m.Status == statusOK
+}
+
+func ManifestPath(outputDir string, source string) string {
+	return filepath.Join(outputDir, source+"-run.json")
+}
+
+// LastOKManifestPath is where the manifest of source's last OK run is kept,
+// so a failed run does not hide what is still published.
+func LastOKManifestPath(outputDir string, source string) string {
+	return filepath.Join(outputDir, source+"-last-ok-run.json")
+}
+
+// ReadManifest loads the manifest at path, one of ManifestPath or
+// LastOKManifestPath.
+func ReadManifest(path string) (Manifest, error) {
+	var manifest Manifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(data, &manifest)
+	return manifest, err
+}
+
+func writeManifest(params Params, manifest Manifest) error {
+	if params.IsDryRun {
+		return nil
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	path := ManifestPath("output", params.SourceName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	if !manifest.OK() {
+		return nil
+	}
+	return os.WriteFile(LastOKManifestPath("output", params.SourceName), data, 0o644)
+}
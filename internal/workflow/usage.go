@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/liuerfire/sieve/internal/llm"
+)
+
+type usageReport struct {
+	Source string `json:"source"`
+	llm.UsageReport
+}
+
+func newUsageTracker(params Params) *llm.UsageTracker {
+	pricing := make(map[string]llm.Price, len(params.LLMConfig.Pricing))
+	for model, price := range params.LLMConfig.Pricing {
+		pricing[model] = llm.Price{Input: price.Input, Output: price.Output}
+	}
+	var budget llm.Budget
+	if params.SourceConfig.Budget != nil {
+		budget = llm.Budget{
+			MaxTokens: params.SourceConfig.Budget.MaxTokens,
+			MaxCost:   params.SourceConfig.Budget.MaxCost,
+		}
+	}
+	return llm.NewUsageTracker(pricing, budget)
+}
+
+func reportUsage(params Params, report llm.UsageReport) error {
+	if report.Total.Calls == 0 {
+		return nil
+	}
+	for _, plugin := range slices.Sorted(maps.Keys(report.Plugins)) {
+		usage := report.Plugins[plugin].Total
+		logInfo(params.Logger, "llm usage", "source", params.SourceName, "plugin", plugin, "calls", usage.Calls, "promptTokens", usage.PromptTokens, "completionTokens", usage.CompletionTokens, "cost", usage.Cost)
+	}
+	logInfo(params.Logger, "llm usage total", "source", params.SourceName, "calls", report.Total.Calls, "promptTokens", report.Total.PromptTokens, "completionTokens", report.Total.CompletionTokens, "cost", report.Total.Cost)
+	if params.IsDryRun {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(usageReport{Source: params.SourceName, UsageReport: report}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join("output", params.SourceName+"-llm-usage.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
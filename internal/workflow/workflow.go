@@ -3,13 +3,16 @@ package workflow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"maps"
+	"slices"
+	"time"
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/llm"
 	"github.com/liuerfire/sieve/internal/plugins"
+	"github.com/liuerfire/sieve/internal/retry"
 	"github.com/liuerfire/sieve/internal/types"
 )
 
@@ -24,13 +27,33 @@ type Params struct {
 	LLMConfig           config.LLMConfig
 	GlobalPluginOptions map[string]json.RawMessage
 	IsDryRun            bool
+	Resume              bool
 	Logger              *slog.Logger
 	LLMFactory          func(tier string) (llm.Provider, error)
 }
 
-func Run(ctx context.Context, params Params) error {
+func Run(ctx context.Context, params Params) (err error) {
 	logInfo(params.Logger, "starting workflow", "source", params.SourceName, "dryRun", params.IsDryRun)
 
+	if maxDuration := time.Duration(params.SourceConfig.MaxDuration); maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, maxDuration, errMaxDuration)
+		defer cancel()
+	}
+
+	usage := newUsageTracker(params)
+	manifest := newManifestRecorder(params.SourceName)
+	var reportTitle string
+	defer func() {
+		usageReport := usage.Report()
+		if reportErr := reportUsage(params, usageReport); reportErr != nil && err == nil {
+			err = reportErr
+		}
+		if manifestErr := writeManifest(params, manifest.finish(reportTitle, usageReport, err)); manifestErr != nil && err == nil {
+			err = manifestErr
+		}
+	}()
+
 	runCtx := plugins.Context{
 		SourceName:    params.SourceName,
 		SourceContext: params.SourceConfig.Context,
@@ -39,69 +62,84 @@ func Run(ctx context.Context, params Params) error {
 		LLM:           params.LLMFactory,
 	}
 
-	sourceEntries := make([]config.PluginEntry, 0, len(params.SourceConfig.Plugins))
-	for _, entry := range params.SourceConfig.Plugins {
-		sourceEntries = append(sourceEntries, config.PluginEntry{
-			Name:    entry.Name,
-			Options: mergeOptions(params.GlobalPluginOptions[entry.Name], entry.Options),
-		})
+	prefixEntries, sourceEntries, err := Entries(params.SourceConfig, params.GlobalPluginOptions)
+	if err != nil {
+		return err
 	}
-
 	sourcePlugins, err := plugins.Load(sourceEntries)
 	if err != nil {
 		return err
 	}
-
-	prefixEntries := make([]config.PluginEntry, 0, len(pipelinePrefix))
-	for _, name := range pipelinePrefix {
-		prefixEntries = append(prefixEntries, config.PluginEntry{
-			Name:    name,
-			Options: params.GlobalPluginOptions[name],
-		})
-	}
-
 	prefixPlugins, err := plugins.Load(prefixEntries)
 	if err != nil {
 		return err
 	}
 
+	pipeline := append(slices.Clone(prefixPlugins), sourcePlugins...)
+	processPlugins := inPhase(pipeline, config.PhaseProcess)
 	var collectedTitle string
-	var items []types.FeedItem
-	for _, loaded := range sourcePlugins {
-		logInfo(params.Logger, "running collect plugin", "source", params.SourceName, "plugin", loaded.Name)
-		result, err := loaded.Plugin.Collect(ctx, loaded.Entry, runCtx)
-		if err != nil {
-			return err
+	var processed []types.FeedItem
+	completed := 0
+	resumed, err := resumeCheckpoint(params, processPlugins)
+	if err != nil {
+		return err
+	}
+	if resumed != nil {
+		collectedTitle = resumed.Title
+		processed = resumed.Items
+		completed = len(resumed.Completed)
+		manifest.resume(resumed)
+		logInfo(params.Logger, "resuming from checkpoint", "source", params.SourceName, "completed", completed, "items", len(processed))
+	} else {
+		var items []types.FeedItem
+		for _, loaded := range inPhase(sourcePlugins, config.PhaseCollect) {
+			logInfo(params.Logger, "running collect plugin", "source", params.SourceName, "plugin", loaded.Name)
+			started := time.Now()
+			result, skipped, err := withErrorPolicy(ctx, params, manifest, stageCollect, loaded, func(ctx context.Context) (plugins.CollectResult, error) {
+				return loaded.Plugin.Collect(ctx, loaded.Entry, runCtx)
+			})
+			manifest.stage(stageCollect, loaded.Name, started, len(items), len(items)+len(result.Items), skipped, err)
+			if err != nil {
+				return err
+			}
+			if skipped {
+				continue
+			}
+			if result.Title != "" {
+				collectedTitle = result.Title
+			}
+			items = append(items, result.Items...)
+			manifest.levels(loaded.Name, items)
+			logInfo(params.Logger, "collect completed", "source", params.SourceName, "plugin", loaded.Name, "items", len(result.Items), "title", result.Title)
 		}
-		if result.Title != "" {
-			collectedTitle = result.Title
+		processed = items
+		if err := saveCheckpoint(params, checkpoint{Title: collectedTitle, Items: processed}); err != nil {
+			return err
 		}
-		items = append(items, result.Items...)
-		logInfo(params.Logger, "collect completed", "source", params.SourceName, "plugin", loaded.Name, "items", len(result.Items), "title", result.Title)
 	}
 
-	processed := items
-	for _, loaded := range prefixPlugins {
+	for i := completed; i < len(processPlugins); i++ {
+		loaded := processPlugins[i]
 		logInfo(params.Logger, "running process plugin", "source", params.SourceName, "plugin", loaded.Name, "items", len(processed))
-		nextItems, err := plugins.ApplyProcessItems(ctx, processed, loaded, runCtx)
-		if err != nil {
-			if isRequiredProcessPlugin(loaded.Name) {
-				return fmt.Errorf("required process plugin %q failed: %w", loaded.Name, err)
-			}
-			continue
+		started := time.Now()
+		nextItems, skipped, err := withErrorPolicy(llm.WithUsageRecorder(ctx, usage.Plugin(loaded.Name)), params, manifest, stageProcess, loaded, func(ctx context.Context) ([]types.FeedItem, error) {
+			return plugins.ApplyProcessItems(ctx, processed, loaded, runCtx)
+		})
+		after := len(processed)
+		if err == nil && !skipped {
+			after = len(nextItems)
 		}
-		processed = nextItems
-	}
-	for _, loaded := range sourcePlugins {
-		logInfo(params.Logger, "running process plugin", "source", params.SourceName, "plugin", loaded.Name, "items", len(processed))
-		nextItems, err := plugins.ApplyProcessItems(ctx, processed, loaded, runCtx)
+		manifest.stage(stageProcess, loaded.Name, started, len(processed), after, skipped, err)
 		if err != nil {
-			if isRequiredProcessPlugin(loaded.Name) {
-				return fmt.Errorf("required process plugin %q failed: %w", loaded.Name, err)
-			}
-			continue
+			return err
+		}
+		if !skipped {
+			manifest.levels(loaded.Name, nextItems)
+			processed = nextItems
+		}
+		if err := saveCheckpoint(params, checkpoint{Title: collectedTitle, Completed: pluginNames(processPlugins[:i+1]), Items: processed}); err != nil {
+			return err
 		}
-		processed = nextItems
 	}
 
 	visibleCount := 0
@@ -115,19 +153,46 @@ func Run(ctx context.Context, params Params) error {
 	}
 	logInfo(params.Logger, "processing completed", "source", params.SourceName, "items", len(processed), "visible", visibleCount, "rejected", rejectedCount)
 
-	reportTitle := params.SourceConfig.Title
+	reportTitle = params.SourceConfig.Title
 	if reportTitle == "" {
 		reportTitle = collectedTitle
 	}
 
-	for _, loaded := range sourcePlugins {
+	var skippedReports []string
+	for _, loaded := range inPhase(sourcePlugins, config.PhaseReport) {
 		logInfo(params.Logger, "running report plugin", "source", params.SourceName, "plugin", loaded.Name, "items", len(processed), "title", reportTitle)
 		reportEntry := loaded.Entry
-		reportEntry.Options = mergeOptions(reportEntry.Options, mustMarshal(map[string]string{
+		reportEntry.Options, err = config.MergeOptions(reportEntry.Options, mustMarshal(map[string]string{
 			"sourceName": params.SourceName,
 			"title":      reportTitle,
 		}))
-		if err := loaded.Plugin.Report(ctx, processed, reportEntry, runCtx); err != nil {
+		if err != nil {
+			return err
+		}
+		started := time.Now()
+		_, skipped, err := withErrorPolicy(ctx, params, manifest, stageReport, loaded, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, loaded.Plugin.Report(ctx, processed, reportEntry, runCtx)
+		})
+		manifest.stage(stageReport, loaded.Name, started, len(processed), len(processed), skipped, err)
+		if err != nil {
+			return err
+		}
+		if skipped {
+			skippedReports = append(skippedReports, loaded.Name)
+		}
+	}
+
+	// A skipped reporter means the items were not fully published, so state
+	// is left as it was and the checkpoint kept: the next run, or --resume,
+	// reports them again.
+	if len(skippedReports) > 0 {
+		manifest.reportsSkipped(skippedReports)
+		logWarn(params.Logger, "reporters were skipped, not committing", "source", params.SourceName, "reporters", skippedReports)
+	} else {
+		if err := commitPlugins(ctx, params, manifest, pipeline, processed, runCtx); err != nil {
+			return err
+		}
+		if err := clearCheckpoint(params); err != nil {
 			return err
 		}
 	}
@@ -144,29 +209,207 @@ func logInfo(logger *slog.Logger, msg string, args ...any) {
 	logger.Info(msg, args...)
 }
 
-func isRequiredProcessPlugin(name string) bool {
+func logWarn(logger *slog.Logger, msg string, args ...any) {
+	if logger == nil {
+		return
+	}
+	logger.Warn(msg, args...)
+}
+
+const (
+	stageCollect = config.PhaseCollect
+	stageProcess = config.PhaseProcess
+	stageReport  = config.PhaseReport
+	stageCommit  = "commit"
+)
+
+// Entries returns the prefix and source plugin entries Run executes for
+// source, with global plugin options merged in by config.MergeOptions. A
+// source without a prefix gets the default one; an explicit empty prefix
+// disables it.
+func Entries(source config.SourceConfig, global map[string]json.RawMessage) (prefix []config.PluginEntry, entries []config.PluginEntry, err error) {
+	if source.Prefix == nil {
+		for _, name := range pipelinePrefix {
+			prefix = append(prefix, config.PluginEntry{Name: name})
+		}
+	} else {
+		prefix = source.Prefix
+	}
+	if prefix, err = mergeEntries(prefix, global); err != nil {
+		return nil, nil, err
+	}
+	if entries, err = mergeEntries(source.Plugins, global); err != nil {
+		return nil, nil, err
+	}
+	return prefix, entries, nil
+}
+
+func mergeEntries(entries []config.PluginEntry, global map[string]json.RawMessage) ([]config.PluginEntry, error) {
+	merged := make([]config.PluginEntry, 0, len(entries))
+	for _, entry := range entries {
+		options, err := config.MergeOptions(global[entry.Name], entry.Options)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %q: %w", entry.Name, err)
+		}
+		entry.Options = options
+		merged = append(merged, entry)
+	}
+	return merged, nil
+}
+
+func inPhase(loaded []plugins.LoadedPlugin, phase string) []plugins.LoadedPlugin {
+	var selected []plugins.LoadedPlugin
+	for _, plugin := range loaded {
+		if plugin.Entry.RunsIn(phase) {
+			selected = append(selected, plugin)
+		}
+	}
+	return selected
+}
+
+var pluginRetryPolicy = retry.Policy{BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// defaultErrorPolicy applies when an entry sets no onError: collect and
+// report failures abort the run, and so do the process plugins whose output
+// later stages depend on. Other process failures are skipped.
+func defaultErrorPolicy(stage string, name string) config.ErrorPolicy {
+	if stage != stageProcess {
+		return config.ErrorPolicy{}
+	}
 	switch name {
 	case "builtin/deduplicate", "builtin/llm-grade", "builtin/llm-summarize":
-		return true
+		return config.ErrorPolicy{}
+	default:
+		return config.ErrorPolicy{Skip: true}
+	}
+}
+
+// withErrorPolicy runs fn under the entry's onError policy. skipped reports
+// that fn failed and the failure was tolerated; err is set only when the run
+// must stop.
+func withErrorPolicy[T any](ctx context.Context, params Params, manifest *manifestRecorder, stage string, loaded plugins.LoadedPlugin, fn func(context.Context) (T, error)) (value T, skipped bool, err error) {
+	policy := defaultErrorPolicy(stage, loaded.Name)
+	if loaded.Entry.OnError != "" {
+		if policy, err = config.ParseErrorPolicy(loaded.Entry.OnError); err != nil {
+			return value, false, fmt.Errorf("plugin %q: %w", loaded.Name, err)
+		}
+	}
+
+	retryPolicy := pluginRetryPolicy
+	retryPolicy.MaxRetries = policy.Retries
+	retryPolicy.Retryable = func(err error) bool {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, llm.ErrBudgetExceeded)
+	}
+	retryPolicy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		manifest.swallowed(stage, loaded.Name, attempt, err)
+		logWarn(params.Logger, "retrying plugin", "source", params.SourceName, "stage", stage, "plugin", loaded.Name, "attempt", attempt, "delay", delay, "error", err)
+	}
+	value, err = retry.Do(ctx, retryPolicy, func(ctx context.Context) (T, error) {
+		return withTimeout(ctx, stage, loaded, fn)
+	})
+	var timeout *TimeoutError
+	switch {
+	case err == nil:
+		return value, false, nil
+	case errors.Is(context.Cause(ctx), errMaxDuration):
+		limit := time.Duration(params.SourceConfig.MaxDuration)
+		logWarn(params.Logger, "source exceeded maxDuration", "source", params.SourceName, "stage", stage, "plugin", loaded.Name, "maxDuration", limit)
+		return value, false, &TimeoutError{Stage: stage, Plugin: loaded.Name, Limit: limit, MaxDuration: true}
+	case errors.As(err, &timeout):
+		logWarn(params.Logger, "plugin timed out", "source", params.SourceName, "stage", stage, "plugin", loaded.Name, "timeout", timeout.Limit)
+		if policy.Skip {
+			manifest.swallowed(stage, loaded.Name, 0, err)
+			return value, true, nil
+		}
+		return value, false, err
+	case errors.Is(err, llm.ErrBudgetExceeded):
+		logWarn(params.Logger, "llm budget exceeded, stopping run", "source", params.SourceName, "stage", stage, "plugin", loaded.Name)
+		return value, false, &BudgetError{Stage: stage, Plugin: loaded.Name}
+	case policy.Skip:
+		logWarn(params.Logger, "plugin failed, skipping", "source", params.SourceName, "stage", stage, "plugin", loaded.Name, "error", err)
+		manifest.swallowed(stage, loaded.Name, 0, err)
+		return value, true, nil
 	default:
-		return false
+		return value, false, fmt.Errorf("required %s plugin %q failed: %w", stage, loaded.Name, err)
 	}
 }
 
-func mergeOptions(global json.RawMessage, local json.RawMessage) json.RawMessage {
-	if len(global) == 0 {
-		return local
+var errMaxDuration = errors.New("source maxDuration exceeded")
+
+// commitPlugins lets every stateful plugin in the pipeline persist what this
+// run published. It runs only when every reporter succeeded, none of them
+// skipped by onError; one failing commit does not stop the others.
+func commitPlugins(ctx context.Context, params Params, manifest *manifestRecorder, pipeline []plugins.LoadedPlugin, items []types.FeedItem, runCtx plugins.Context) error {
+	if params.IsDryRun {
+		return nil
 	}
-	if len(local) == 0 {
-		return global
+	var errs []error
+	for _, loaded := range pipeline {
+		committer, ok := loaded.Plugin.(plugins.Committer)
+		if !ok {
+			continue
+		}
+		started := time.Now()
+		err := committer.Commit(ctx, items, loaded.Entry, runCtx)
+		manifest.stage(stageCommit, loaded.Name, started, len(items), len(items), false, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("commit plugin %q failed: %w", loaded.Name, err))
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	merged := map[string]any{}
-	_ = json.Unmarshal(global, &merged)
-	localValues := map[string]any{}
-	_ = json.Unmarshal(local, &localValues)
-	maps.Copy(merged, localValues)
-	return mustMarshal(merged)
+// TimeoutError reports that a plugin overran its own timeout or, when
+// MaxDuration is set, that the source's maxDuration ran out while it was
+// running.
+type TimeoutError struct {
+	Stage       string
+	Plugin      string
+	Limit       time.Duration
+	MaxDuration bool
+}
+
+func (e *TimeoutError) Error() string {
+	if e.MaxDuration {
+		return fmt.Sprintf("maxDuration %s exceeded during %s plugin %q", e.Limit, e.Stage, e.Plugin)
+	}
+	return fmt.Sprintf("%s plugin %q timed out after %s", e.Stage, e.Plugin, e.Limit)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// BudgetError reports that the source's LLM budget ran out while a plugin
+// was running. The run stops before reporting so that nothing half graded or
+// half summarized is published or committed; the checkpoint and the response
+// cache keep what was already paid for.
+type BudgetError struct {
+	Stage  string
+	Plugin string
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("llm budget exceeded during %s plugin %q", e.Stage, e.Plugin)
+}
+
+func (e *BudgetError) Unwrap() error {
+	return llm.ErrBudgetExceeded
+}
+
+// withTimeout runs a single attempt of fn under the entry's timeout.
+func withTimeout[T any](ctx context.Context, stage string, loaded plugins.LoadedPlugin, fn func(context.Context) (T, error)) (T, error) {
+	limit := time.Duration(loaded.Entry.Timeout)
+	if limit <= 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+	value, err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return value, &TimeoutError{Stage: stage, Plugin: loaded.Name, Limit: limit}
+	}
+	return value, err
 }
 
 func mustMarshal(value any) json.RawMessage {
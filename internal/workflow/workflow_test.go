@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/liuerfire/sieve/internal/config"
 	"github.com/liuerfire/sieve/internal/llm"
@@ -16,6 +20,21 @@ import (
 	"github.com/liuerfire/sieve/internal/types"
 )
 
+// TestMain runs the tests from a scratch directory because Run writes its
+// usage and manifest files under ./output.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "workflow-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 type recorderPlugin struct {
 	collectResult plugins.CollectResult
 	events        *[]string
@@ -247,14 +266,567 @@ func TestRunWorkflow_ReturnsErrorForUnsupportedLLMProvider(t *testing.T) {
 			},
 		},
 		LLMConfig: config.LLMConfig{
-			Provider: "openai",
+			Provider: "unknown",
 		},
 		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
 		LLMFactory: func(string) (llm.Provider, error) {
-			return llm.CreateProvider(llm.Config{Provider: "openai", Model: "test-model"})
+			return llm.CreateProvider(llm.Config{Provider: "unknown", Model: "test-model"})
 		},
 	})
-	if err == nil || !strings.Contains(err.Error(), `unsupported provider "openai"`) {
+	if err == nil || !strings.Contains(err.Error(), `unsupported provider "unknown"`) {
 		t.Fatalf("expected unsupported provider error, got %v", err)
 	}
 }
+
+type budgetPlugin struct {
+	plugins.BasePlugin
+}
+
+func (budgetPlugin) ProcessItems(ctx context.Context, _ []types.FeedItem, _ config.PluginEntry, runCtx plugins.Context) ([]types.FeedItem, error) {
+	provider, err := runCtx.LLM("balanced")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := provider.Grade(ctx, llm.GradeRequest{}); err != nil {
+		return nil, fmt.Errorf("grade: %w", err)
+	}
+	return nil, nil
+}
+
+func TestRunWorkflow_StopsOverBudget(t *testing.T) {
+	var events []string
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("builtin/llm-grade", budgetPlugin{})
+	plugins.Register("source/test", recorderPlugin{events: &events})
+	plugins.Register("source/committer", committingPlugin{recorderPlugin: recorderPlugin{events: &events}})
+
+	err := Run(context.Background(), Params{
+		SourceName: "budget",
+		SourceConfig: config.SourceConfig{
+			Name: "budget",
+			Plugins: []config.PluginEntry{
+				{Name: "builtin/llm-grade", OnError: "skip"},
+				{Name: "source/test"},
+				{Name: "source/committer"},
+			},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		LLMFactory: func(string) (llm.Provider, error) {
+			return llm.FallbackProvider{Backends: []llm.Backend{{Name: "static", Provider: budgetProvider{}}}}, nil
+		},
+	})
+	var budget *BudgetError
+	if !errors.As(err, &budget) || budget.Plugin != "builtin/llm-grade" || !errors.Is(err, llm.ErrBudgetExceeded) {
+		t.Fatalf("expected a budget error from llm-grade, got %v", err)
+	}
+	if slices.ContainsFunc(events, func(event string) bool {
+		return strings.HasPrefix(event, "report:") || strings.HasPrefix(event, "commit:")
+	}) {
+		t.Fatalf("expected the run to stop before report and commit, got %v", events)
+	}
+	if _, err := os.Stat(CheckpointPath("output", "budget")); err != nil {
+		t.Fatalf("expected the checkpoint to be kept for --resume: %v", err)
+	}
+}
+
+type budgetProvider struct{}
+
+func (budgetProvider) Grade(context.Context, llm.GradeRequest) ([]llm.GradeResult, error) {
+	return nil, llm.ErrBudgetExceeded
+}
+
+func (budgetProvider) Summarize(context.Context, llm.SummaryRequest) (llm.SummaryResult, error) {
+	return llm.SummaryResult{}, llm.ErrBudgetExceeded
+}
+
+type failingPlugin struct {
+	collectErr error
+	reportErr  error
+	failures   *int
+	events     *[]string
+}
+
+func (p failingPlugin) fail(err error) error {
+	if err == nil || p.failures == nil || *p.failures <= 0 {
+		return nil
+	}
+	*p.failures--
+	return err
+}
+
+func (p failingPlugin) Collect(_ context.Context, entry config.PluginEntry, _ plugins.Context) (plugins.CollectResult, error) {
+	if err := p.fail(p.collectErr); err != nil {
+		return plugins.CollectResult{}, err
+	}
+	if p.events != nil {
+		*p.events = append(*p.events, "collect:"+entry.Name)
+	}
+	return plugins.CollectResult{Items: []types.FeedItem{types.FeedItem{Title: entry.Name}.WithDefaults()}}, nil
+}
+
+func (failingPlugin) ProcessItems(_ context.Context, items []types.FeedItem, _ config.PluginEntry, _ plugins.Context) ([]types.FeedItem, error) {
+	return items, nil
+}
+
+func (p failingPlugin) Report(_ context.Context, _ []types.FeedItem, entry config.PluginEntry, _ plugins.Context) error {
+	if err := p.fail(p.reportErr); err != nil {
+		return err
+	}
+	if p.events != nil {
+		*p.events = append(*p.events, "report:"+entry.Name)
+	}
+	return nil
+}
+
+func TestRunWorkflow_OnErrorSkipKeepsOtherPluginsRunning(t *testing.T) {
+	var events []string
+	collectFailures, reportFailures := 1, 1
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("source/broken-collector", failingPlugin{collectErr: errors.New("feed down"), failures: &collectFailures})
+	plugins.Register("source/good-collector", failingPlugin{events: &events})
+	plugins.Register("source/broken-reporter", failingPlugin{reportErr: errors.New("disk full"), failures: &reportFailures})
+	plugins.Register("source/good-reporter", failingPlugin{events: &events})
+
+	err := Run(context.Background(), Params{
+		SourceName: "source",
+		SourceConfig: config.SourceConfig{
+			Name: "source",
+			Plugins: []config.PluginEntry{
+				{Name: "source/broken-collector", OnError: "skip"},
+				{Name: "source/good-collector"},
+				{Name: "source/broken-reporter", OnError: "skip"},
+				{Name: "source/good-reporter"},
+			},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("expected skipped failures not to abort the run, got %v", err)
+	}
+	for _, want := range []string{"collect:source/good-collector", "report:source/good-reporter"} {
+		if !slices.Contains(events, want) {
+			t.Fatalf("expected %q in events %v", want, events)
+		}
+	}
+}
+
+func TestRunWorkflow_OnErrorFailAbortsCollect(t *testing.T) {
+	failures := 1
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("source/broken-collector", failingPlugin{collectErr: errors.New("feed down"), failures: &failures})
+
+	err := Run(context.Background(), Params{
+		SourceName: "source",
+		SourceConfig: config.SourceConfig{
+			Name:    "source",
+			Plugins: []config.PluginEntry{{Name: "source/broken-collector"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err == nil || !strings.Contains(err.Error(), `required collect plugin "source/broken-collector" failed: feed down`) {
+		t.Fatalf("expected collect failure by default, got %v", err)
+	}
+}
+
+func TestRunWorkflow_OnErrorRetryRetriesThenFails(t *testing.T) {
+	prev := pluginRetryPolicy
+	pluginRetryPolicy.BaseDelay = 0
+	defer func() { pluginRetryPolicy = prev }()
+
+	var events []string
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	flaky := 2
+	plugins.Register("source/flaky", failingPlugin{reportErr: errors.New("timeout"), failures: &flaky, events: &events})
+
+	params := Params{
+		SourceName: "source",
+		SourceConfig: config.SourceConfig{
+			Name:    "source",
+			Plugins: []config.PluginEntry{{Name: "source/flaky", OnError: "retry:2"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := Run(context.Background(), params); err != nil {
+		t.Fatalf("expected retries to recover, got %v", err)
+	}
+	if !slices.Contains(events, "report:source/flaky") {
+		t.Fatalf("expected report to succeed after retries, got %v", events)
+	}
+
+	flaky = 3
+	if err := Run(context.Background(), params); err == nil || !strings.Contains(err.Error(), `required report plugin "source/flaky" failed`) {
+		t.Fatalf("expected exhausted retries to fail, got %v", err)
+	}
+}
+
+func TestRunWorkflow_OnErrorFailOverridesOptionalProcessPlugin(t *testing.T) {
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("source/test", recorderPlugin{processErr: errors.New("enrich failed")})
+
+	err := Run(context.Background(), Params{
+		SourceName: "source",
+		SourceConfig: config.SourceConfig{
+			Name:    "source",
+			Plugins: []config.PluginEntry{{Name: "source/test", OnError: "fail"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err == nil || !strings.Contains(err.Error(), `required process plugin "source/test" failed`) {
+		t.Fatalf("expected explicit fail policy to abort, got %v", err)
+	}
+}
+
+type blockingPlugin struct {
+	plugins.BasePlugin
+	stage string
+}
+
+func (p blockingPlugin) Collect(ctx context.Context, _ config.PluginEntry, _ plugins.Context) (plugins.CollectResult, error) {
+	if p.stage == stageCollect {
+		<-ctx.Done()
+		return plugins.CollectResult{}, ctx.Err()
+	}
+	return plugins.CollectResult{Items: []types.FeedItem{types.FeedItem{Title: "item"}.WithDefaults()}}, nil
+}
+
+func (p blockingPlugin) ProcessItems(ctx context.Context, items []types.FeedItem, _ config.PluginEntry, _ plugins.Context) ([]types.FeedItem, error) {
+	if p.stage == stageProcess {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return items, nil
+}
+
+func TestRunWorkflow_PluginTimeout(t *testing.T) {
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("source/slow", blockingPlugin{stage: stageProcess})
+
+	params := Params{
+		SourceName: "source",
+		SourceConfig: config.SourceConfig{
+			Name:    "source",
+			Plugins: []config.PluginEntry{{Name: "source/slow", Timeout: config.Duration(10 * time.Millisecond)}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := Run(context.Background(), params); err != nil {
+		t.Fatalf("expected optional plugin timeout to be skipped, got %v", err)
+	}
+
+	params.SourceConfig.Plugins[0].OnError = "fail"
+	err := Run(context.Background(), params)
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || timeout.Stage != stageProcess || timeout.Plugin != "source/slow" || timeout.MaxDuration {
+		t.Fatalf("expected process timeout error, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), `process plugin "source/slow" timed out after 10ms`) {
+		t.Fatalf("unexpected timeout error: %v", err)
+	}
+}
+
+func TestRunWorkflow_MaxDurationNamesStage(t *testing.T) {
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("source/slow", blockingPlugin{stage: stageCollect})
+
+	err := Run(context.Background(), Params{
+		SourceName: "source",
+		SourceConfig: config.SourceConfig{
+			Name:        "source",
+			MaxDuration: config.Duration(10 * time.Millisecond),
+			Plugins:     []config.PluginEntry{{Name: "source/slow", OnError: "skip"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || !timeout.MaxDuration || timeout.Stage != stageCollect {
+		t.Fatalf("expected maxDuration error in collect stage, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `maxDuration 10ms exceeded during collect plugin "source/slow"`) {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+}
+
+type committingPlugin struct {
+	recorderPlugin
+	commitErr error
+}
+
+func (p committingPlugin) Commit(_ context.Context, items []types.FeedItem, entry config.PluginEntry, _ plugins.Context) error {
+	if p.events != nil {
+		*p.events = append(*p.events, fmt.Sprintf("commit:%s:%d", entry.Name, len(items)))
+	}
+	return p.commitErr
+}
+
+type levelPlugin struct {
+	plugins.BasePlugin
+	levels map[string]types.FeedLevel
+}
+
+func (p levelPlugin) ProcessItems(_ context.Context, items []types.FeedItem, _ config.PluginEntry, _ plugins.Context) ([]types.FeedItem, error) {
+	next := slices.Clone(items)
+	for i := range next {
+		if level, ok := p.levels[next[i].GUID]; ok {
+			next[i].Level = level
+		}
+	}
+	return next, nil
+}
+
+func TestRunWorkflow_WritesRunManifest(t *testing.T) {
+	plugins.Register("builtin/deduplicate", recorderPlugin{})
+	plugins.Register("builtin/clean-text", levelPlugin{})
+	plugins.Register("source/collector", recorderPlugin{collectResult: plugins.CollectResult{Title: "Collected", Items: []types.FeedItem{
+		types.FeedItem{GUID: "a", Title: "A"}.WithDefaults(),
+		types.FeedItem{GUID: "b", Title: "B"}.WithDefaults(),
+	}}})
+	plugins.Register("source/grader", levelPlugin{levels: map[string]types.FeedLevel{"a": types.LevelCritical, "b": types.LevelRejected}})
+	plugins.Register("source/broken", recorderPlugin{processErr: errors.New("enrich failed")})
+
+	err := Run(context.Background(), Params{
+		SourceName: "manifest",
+		SourceConfig: config.SourceConfig{
+			Name:    "manifest",
+			Plugins: []config.PluginEntry{{Name: "source/collector"}, {Name: "source/grader"}, {Name: "source/broken"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(ManifestPath("output", "manifest"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if manifest.Status != "ok" || manifest.Title != "Collected" || manifest.FinishedAt.Before(manifest.StartedAt) {
+		t.Fatalf("unexpected manifest header: %+v", manifest)
+	}
+	if manifest.Counts != (ItemCounts{Total: 4, Visible: 3, Rejected: 1}) {
+		t.Fatalf("unexpected counts: %+v", manifest.Counts)
+	}
+
+	var stages []string
+	for _, stage := range manifest.Stages {
+		stages = append(stages, fmt.Sprintf("%s:%s:%s:%d->%d", stage.Stage, stage.Plugin, stage.Status, stage.ItemsBefore, stage.ItemsAfter))
+	}
+	want := []string{
+		"collect:source/collector:ok:0->2",
+		"collect:source/grader:ok:2->2",
+		"collect:source/broken:ok:2->2",
+		"process:builtin/deduplicate:ok:2->3",
+		"process:builtin/clean-text:ok:3->3",
+		"process:source/collector:ok:3->4",
+		"process:source/grader:ok:4->4",
+		"process:source/broken:skipped:4->4",
+		"report:source/collector:ok:4->4",
+		"report:source/grader:ok:4->4",
+		"report:source/broken:ok:4->4",
+	}
+	if !reflect.DeepEqual(stages, want) {
+		t.Fatalf("unexpected stages:\n%v\nwant\n%v", stages, want)
+	}
+
+	wantTransitions := []LevelTransition{{Plugin: "source/grader", From: types.LevelUnknown, To: types.LevelCritical}}
+	if manifest.Items[0].GUID != "a" || !reflect.DeepEqual(manifest.Items[0].Transitions, wantTransitions) {
+		t.Fatalf("unexpected item record: %+v", manifest.Items[0])
+	}
+	if len(manifest.Errors) != 1 || manifest.Errors[0].Plugin != "source/broken" || manifest.Errors[0].Error != "enrich failed" {
+		t.Fatalf("unexpected swallowed errors: %+v", manifest.Errors)
+	}
+}
+
+func TestRunWorkflow_ResumesFromCheckpointAndCommitsAfterReport(t *testing.T) {
+	var events []string
+	plugins.Register("builtin/deduplicate", committingPlugin{recorderPlugin: recorderPlugin{events: &events}})
+	plugins.Register("builtin/clean-text", recorderPlugin{events: &events})
+	plugins.Register("source/collector", recorderPlugin{events: &events, collectResult: plugins.CollectResult{Items: []types.FeedItem{
+		types.FeedItem{GUID: "a", Title: "A"}.WithDefaults(),
+	}}})
+	plugins.Register("source/summarize", recorderPlugin{events: &events, processErr: errors.New("provider down")})
+
+	params := Params{
+		SourceName: "resume",
+		SourceConfig: config.SourceConfig{
+			Name:    "resume",
+			Plugins: []config.PluginEntry{{Name: "source/collector"}, {Name: "source/summarize", OnError: "fail"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := Run(context.Background(), params); err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+	if _, err := os.Stat(CheckpointPath("output", "resume")); err != nil {
+		t.Fatalf("expected a checkpoint after the failed run: %v", err)
+	}
+	if slices.ContainsFunc(events, func(event string) bool { return strings.HasPrefix(event, "commit:") }) {
+		t.Fatalf("expected no commit for a failed run, got %v", events)
+	}
+
+	events = nil
+	plugins.Register("source/summarize", recorderPlugin{events: &events})
+	params.Resume = true
+	if err := Run(context.Background(), params); err != nil {
+		t.Fatalf("resumed run returned error: %v", err)
+	}
+	want := []string{
+		"process:source/summarize",
+		"report:source/collector",
+		"report:source/summarize",
+		"commit:builtin/deduplicate:5",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("expected resume to skip completed stages and commit last, got %v", events)
+	}
+	if _, err := os.Stat(CheckpointPath("output", "resume")); !os.IsNotExist(err) {
+		t.Fatalf("expected the checkpoint to be removed, got err=%v", err)
+	}
+}
+
+func TestRunWorkflow_SkipsCommitWhenReportFails(t *testing.T) {
+	var events []string
+	reportFailures := 1
+	plugins.Register("builtin/deduplicate", committingPlugin{recorderPlugin: recorderPlugin{events: &events}})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("source/reporter", failingPlugin{reportErr: errors.New("disk full"), failures: &reportFailures})
+	plugins.Register("source/stateful", committingPlugin{recorderPlugin: recorderPlugin{events: &events}, commitErr: errors.New("state locked")})
+
+	params := Params{
+		SourceName: "commit",
+		SourceConfig: config.SourceConfig{
+			Name:    "commit",
+			Plugins: []config.PluginEntry{{Name: "source/reporter"}, {Name: "source/stateful"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := Run(context.Background(), params); err == nil {
+		t.Fatal("expected the report failure to fail the run")
+	}
+	if slices.ContainsFunc(events, func(event string) bool { return strings.HasPrefix(event, "commit:") }) {
+		t.Fatalf("expected no commit after a failed report, got %v", events)
+	}
+
+	events = nil
+	err := Run(context.Background(), params)
+	if err == nil || !strings.Contains(err.Error(), `commit plugin "source/stateful" failed: state locked`) {
+		t.Fatalf("expected commit failure, got %v", err)
+	}
+	if !slices.Contains(events, "commit:builtin/deduplicate:4") {
+		t.Fatalf("expected other plugins to commit despite the failure, got %v", events)
+	}
+}
+
+func TestRunWorkflow_SkipsCommitWhenReporterIsSkipped(t *testing.T) {
+	var events []string
+	reportFailures := 1
+	plugins.Register("builtin/deduplicate", committingPlugin{recorderPlugin: recorderPlugin{events: &events}})
+	plugins.Register("builtin/clean-text", recorderPlugin{})
+	plugins.Register("source/reporter", failingPlugin{reportErr: errors.New("disk full"), failures: &reportFailures})
+
+	params := Params{
+		SourceName: "skipped-report",
+		SourceConfig: config.SourceConfig{
+			Name:    "skipped-report",
+			Plugins: []config.PluginEntry{{Name: "source/reporter", OnError: "skip"}},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := Run(context.Background(), params); err != nil {
+		t.Fatalf("expected the skipped reporter not to fail the run, got %v", err)
+	}
+	if slices.ContainsFunc(events, func(event string) bool { return strings.HasPrefix(event, "commit:") }) {
+		t.Fatalf("expected no commit after a skipped report, got %v", events)
+	}
+	if _, err := os.Stat(CheckpointPath("output", "skipped-report")); err != nil {
+		t.Fatalf("expected the checkpoint to be kept: %v", err)
+	}
+	manifest, err := ReadManifest(ManifestPath("output", "skipped-report"))
+	if err != nil || manifest.OK() || manifest.Status != statusIncomplete || !strings.Contains(manifest.Error, "source/reporter") {
+		t.Fatalf("expected an incomplete manifest naming the reporter, got %+v, err %v", manifest, err)
+	}
+	if _, err := os.Stat(LastOKManifestPath("output", "skipped-report")); !os.IsNotExist(err) {
+		t.Fatalf("expected no last OK manifest yet, got err=%v", err)
+	}
+
+	if err := Run(context.Background(), params); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !slices.ContainsFunc(events, func(event string) bool { return strings.HasPrefix(event, "commit:builtin/deduplicate") }) {
+		t.Fatalf("expected a commit once every reporter succeeded, got %v", events)
+	}
+	if manifest, err := ReadManifest(LastOKManifestPath("output", "skipped-report")); err != nil || !manifest.OK() {
+		t.Fatalf("expected the OK run to be kept as the last OK manifest, got %+v, err %v", manifest, err)
+	}
+}
+
+func TestRunWorkflow_HonoursPhasesAndPrefixOverride(t *testing.T) {
+	var events []string
+	plugins.Register("builtin/deduplicate", recorderPlugin{events: &events})
+	plugins.Register("builtin/clean-text", recorderPlugin{events: &events})
+	plugins.Register("source/scraper", recorderPlugin{events: &events})
+	plugins.Register("source/reporter", recorderPlugin{events: &events})
+
+	err := Run(context.Background(), Params{
+		SourceName: "phases",
+		SourceConfig: config.SourceConfig{
+			Name:   "phases",
+			Prefix: []config.PluginEntry{},
+			Plugins: []config.PluginEntry{
+				{Name: "source/scraper", Phases: []string{"collect", "process"}},
+				{Name: "builtin/clean-text", Phases: []string{"process"}},
+				{Name: "source/reporter", Phases: []string{"report"}},
+			},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	want := []string{
+		"collect:source/scraper",
+		"process:source/scraper",
+		"process:builtin/clean-text",
+		"report:source/reporter",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("unexpected plugin order:\n%v\nwant\n%v", events, want)
+	}
+}
+
+func TestEntries_DeepMergesGlobalOptions(t *testing.T) {
+	global := map[string]json.RawMessage{
+		"builtin/llm-grade": json.RawMessage(`{"interest": ["AI"], "batchSize": 10, "limits": {"a": 1, "b": 2}}`),
+	}
+	source := config.SourceConfig{
+		Name:   "news",
+		Prefix: []config.PluginEntry{},
+		Plugins: []config.PluginEntry{
+			{Name: "builtin/llm-grade", Options: json.RawMessage(`{"interest+": ["Rust"], "limits": {"b": 3}}`)},
+		},
+	}
+
+	_, entries, err := Entries(source, global)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"batchSize":10,"interest":["AI","Rust"],"limits":{"a":1,"b":3}}`
+	if got := string(entries[0].Options); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	source.Plugins[0].Options = json.RawMessage(`"not an object"`)
+	if _, _, err := Entries(source, global); err == nil || !strings.Contains(err.Error(), `plugin "builtin/llm-grade": options must be an object, got string`) {
+		t.Fatalf("expected invalid options to be reported, got %v", err)
+	}
+}